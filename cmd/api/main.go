@@ -1,20 +1,102 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/jvanrhyn/woordsoek/internal/api" // Import the new api package
-	configure "github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/telemetry"
 	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 func main() {
+	cfg, args, err := config.Load("woordsoek-api", os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-	logger := configure.SetupLogging()
-	slog.SetDefault(logger)
+	// woordsoek-api new-key <id> prints a new API key and its config entry
+	if len(args) > 0 && args[0] == "new-key" {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "usage: woordsoek-api new-key <id>")
+			os.Exit(2)
+		}
+		if err := newKey(args[1]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logger, err := config.SetupLogging(cfg.Log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to set up logging:", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(telemetry.NewLogger(logger))
+	woordsoek.SetTraceSample(cfg.Log.TraceSample)
+
+	shutdownTracing, err := telemetry.SetupTracing(context.Background(), "woordsoek-api", cfg.Tracing)
+	if err != nil {
+		slog.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
 
 	slog.Info("Starting Woordsoek API server")
 	woordsoek.LoadVowelForms() // Initialize vowel forms
 	slog.Info("Vowel forms loaded")
-	api.StartAPIServer() // Start the API server
+
+	// One registry for the process, shared by the engine and the HTTP server
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	engine := woordsoek.NewEngine(cfg.DictionaryDir,
+		woordsoek.WithMetrics(woordsoek.NewMetrics(reg)),
+		woordsoek.WithResultCache(cfg.Cache.Size),
+	)
+
+	// Stop gracefully on Ctrl+C or when the orchestrator sends SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server, err := api.New(cfg, engine, reg)
+	if err == nil {
+		err = server.Run(ctx) // Start the API server
+	}
+	if err != nil {
+		slog.Error("API server failed", "error", err)
+		stop()
+		_ = shutdownTracing(context.Background())
+		os.Exit(1)
+	}
+}
+
+// newKey generates an API key. Only the hash is meant to be stored; the key
+// itself is shown once and handed to the client.
+func newKey(id string) error {
+	key, err := api.GenerateKey()
+	if err != nil {
+		return err
+	}
+	fmt.Println("API key (give this to the client, it is not stored):")
+	fmt.Println("  " + key)
+	fmt.Println()
+	fmt.Println("Add this entry to api.auth.keys or the api.auth.keys_file list:")
+	fmt.Printf("  - id: %s\n    hash: %s\n", id, api.HashKey(key))
+	return nil
 }
@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+
+	"github.com/jvanrhyn/woordsoek/internal/config"
 )
 
-func cleanUp(filename string, outputFileName string) {
+func cleanUp(filename string, outputDir string, outputFileName string) {
 	// Open the file for reading
 	file, err := os.Open(filename)
 	if err != nil {
@@ -19,7 +22,7 @@ func cleanUp(filename string, outputFileName string) {
 	}(file)
 
 	// Create a new file to write the cleaned content
-	outputFile, err := os.Create("dictionaries/" + outputFileName + ".txt")
+	outputFile, err := os.Create(filepath.Join(outputDir, outputFileName+".txt"))
 	if err != nil {
 		fmt.Println("Error creating output file:", err)
 		return
@@ -64,13 +67,19 @@ func cleanUp(filename string, outputFileName string) {
 }
 
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: go run cleaner.go <input_file> <output_file>")
+	cfg, args, err := config.Load("cleaner", os.Args[1:])
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	if len(args) < 2 {
+		fmt.Println("Usage: go run cleaner.go [flags] <input_file> <output_file>")
 		return
 	}
 
-	inputFile := os.Args[1]
-	outputFile := os.Args[2]
+	inputFile := args[0]
+	outputFile := args[1]
 
-	cleanUp(inputFile, outputFile)
+	cleanUp(inputFile, cfg.DictionaryDir, outputFile)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -9,29 +10,95 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/telemetry"
 	_ "github.com/lib/pq" // PostgreSQL driver
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/jvanrhyn/woordsoek/cmd/importer")
+
+// dbSpan starts a client span for a database call.
+func dbSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation), semconv.DBCollectionName("words"))
+	return tracer.Start(ctx, operation+" words", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// insertBatch inserts batch, a flat list of (word, locale, in_use) triples,
+// with a single statement.
+func insertBatch(ctx context.Context, db *sql.DB, batch []interface{}) error {
+	rows := len(batch) / 3
+	ctx, span := dbSpan(ctx, "INSERT", attribute.Int("db.rows", rows))
+	defer span.End()
+
+	query := "INSERT INTO words (word, locale, in_use) VALUES "
+	valueStrings := make([]string, rows)
+	for i := 0; i < rows; i++ {
+		valueStrings[i] = fmt.Sprintf("($%d, $%d, $%d)", i*3+1, i*3+2, i*3+3)
+	}
+	_, err := db.ExecContext(ctx, query+strings.Join(valueStrings, ","), batch...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
 func main() {
-	// Database connection parameters
-	connStr := "user=johanvanrhyn dbname=woordsoek sslmode=disable" // Update with your username
-	db, err := sql.Open("postgres", connStr)
+	cfg, _, err := config.Load("woordsoek-importer", os.Args[1:])
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// Spans are flushed before exiting, so errors below return from run
+	// rather than calling log.Fatal.
+	shutdownTracing, err := telemetry.SetupTracing(context.Background(), "woordsoek-importer", cfg.Tracing)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = run(cfg)
+	if serr := shutdownTracing(context.Background()); serr != nil {
+		log.Printf("Error flushing traces: %v\n", serr)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Words imported successfully.")
+}
+
+func run(cfg *config.Config) error {
+	ctx, span := tracer.Start(context.Background(), "import")
+	defer span.End()
+
+	// Database connection parameters come from database.dsn
+	db, err := sql.Open("postgres", cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
 	defer func(db *sql.DB) {
 		_ = db.Close()
 	}(db)
 
 	// Check if the connection is successful
-	if err := db.Ping(); err != nil {
-		log.Fatal(err)
+	pingCtx, pingSpan := dbSpan(ctx, "PING")
+	err = db.PingContext(pingCtx)
+	if err != nil {
+		pingSpan.RecordError(err)
+		pingSpan.SetStatus(codes.Error, err.Error())
+	}
+	pingSpan.End()
+	if err != nil {
+		return err
 	}
 
 	var wg sync.WaitGroup
 
 	// Iterate over files in the dictionaries folder
-	err = filepath.Walk("dictionaries", func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(cfg.DictionaryDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -40,6 +107,9 @@ func main() {
 			go func(path string, locale string) {
 				defer wg.Done() // Decrement the counter when the goroutine completes
 
+				ctx, span := tracer.Start(ctx, "import locale", trace.WithAttributes(attribute.String("dictionary.locale", locale)))
+				defer span.End()
+
 				// Read file content
 				content, err := os.ReadFile(path)
 				if err != nil {
@@ -61,13 +131,7 @@ func main() {
 
 					// Execute batch insert when batch size is reached
 					if insertCount%batchSize == 0 {
-						query := "INSERT INTO words (word, locale, in_use) VALUES "
-						valueStrings := make([]string, batchSize)
-						for i := 0; i < batchSize; i++ {
-							valueStrings[i] = fmt.Sprintf("($%d, $%d, $%d)", i*3+1, i*3+2, i*3+3)
-						}
-						_, err := db.Exec(query+strings.Join(valueStrings, ","), batch...)
-						if err != nil {
+						if err := insertBatch(ctx, db, batch); err != nil {
 							log.Printf("Error inserting batch for locale %s: %v\n", locale, err)
 							return
 						}
@@ -78,13 +142,7 @@ func main() {
 
 				// Insert any remaining words in the batch
 				if len(batch) > 0 {
-					query := "INSERT INTO words (word, locale, in_use) VALUES "
-					valueStrings := make([]string, len(batch)/3)
-					for i := 0; i < len(batch)/3; i++ {
-						valueStrings[i] = fmt.Sprintf("($%d, $%d, $%d)", i*3+1, i*3+2, i*3+3)
-					}
-					_, err := db.Exec(query+strings.Join(valueStrings, ","), batch...)
-					if err != nil {
+					if err := insertBatch(ctx, db, batch); err != nil {
 						log.Printf("Error inserting remaining batch for locale %s: %v\n", locale, err)
 						return
 					}
@@ -95,10 +153,6 @@ func main() {
 		return nil
 	})
 
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	wg.Wait() // Wait for all goroutines to finish
-	fmt.Println("Words imported successfully.")
+	return err
 }
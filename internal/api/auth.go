@@ -0,0 +1,261 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// apiKeyKey is the Fiber local holding the *keyEntry of an authenticated
+// request.
+const apiKeyKey = "apiKey"
+
+// HashKey returns the hex SHA-256 of an API key, as stored in the
+// configuration.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateKey returns a new random API key.
+func GenerateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "ws_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// KeyUsage is an API key with its usage since the server started, as listed
+// by GET /admin/keys.
+type KeyUsage struct {
+	config.APIKey
+	Source      string     `json:"source"`
+	Requests    int64      `json:"requests"`
+	RateLimited int64      `json:"rateLimited"`
+	LastUsed    *time.Time `json:"lastUsed,omitempty"`
+}
+
+type keyEntry struct {
+	config.APIKey
+	fromFile bool
+
+	requests    int64
+	rateLimited int64
+	lastUsed    time.Time
+}
+
+// keyStore holds the configured keys, indexed by hash for authentication
+// and by ID for administration.
+type keyStore struct {
+	mu       sync.Mutex
+	keysFile string
+	byHash   map[string]*keyEntry
+	byID     map[string]*keyEntry
+}
+
+// newKeyStore loads the keys from the configuration and the keys file.
+func newKeyStore(cfg config.AuthConfig) (*keyStore, error) {
+	ks := &keyStore{
+		keysFile: cfg.KeysFile,
+		byHash:   make(map[string]*keyEntry),
+		byID:     make(map[string]*keyEntry),
+	}
+
+	var fileKeys []config.APIKey
+	if cfg.KeysFile != "" {
+		data, err := os.ReadFile(cfg.KeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading API keys file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &fileKeys); err != nil {
+			return nil, fmt.Errorf("parsing API keys file %s: %w", cfg.KeysFile, err)
+		}
+	}
+
+	add := func(key config.APIKey, fromFile bool) error {
+		if key.ID == "" || key.Hash == "" {
+			return fmt.Errorf("API key %q needs an id and a hash", key.ID)
+		}
+		if _, ok := ks.byID[key.ID]; ok {
+			return fmt.Errorf("API key id %q is used more than once", key.ID)
+		}
+		entry := &keyEntry{APIKey: key, fromFile: fromFile}
+		entry.Hash = strings.ToLower(entry.Hash)
+		ks.byHash[entry.Hash] = entry
+		ks.byID[entry.ID] = entry
+		return nil
+	}
+	for _, key := range cfg.Keys {
+		if err := add(key, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range fileKeys {
+		if err := add(key, true); err != nil {
+			return nil, err
+		}
+	}
+	return ks, nil
+}
+
+// lookup returns the active key matching the plain-text key.
+func (ks *keyStore) lookup(key string) (*keyEntry, bool) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	entry, ok := ks.byHash[HashKey(key)]
+	if !ok || entry.Revoked {
+		return nil, false
+	}
+	return entry, true
+}
+
+// record counts a request made with the key with the given ID.
+func (ks *keyStore) record(id string, allowed bool) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	entry, ok := ks.byID[id]
+	if !ok {
+		return
+	}
+	entry.requests++
+	if !allowed {
+		entry.rateLimited++
+	}
+	entry.lastUsed = time.Now()
+}
+
+// list returns every key, including revoked ones, sorted by ID.
+func (ks *keyStore) list() []KeyUsage {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	usage := make([]KeyUsage, 0, len(ks.byID))
+	for _, entry := range ks.byID {
+		u := KeyUsage{
+			APIKey:      entry.APIKey,
+			Source:      "config",
+			Requests:    entry.requests,
+			RateLimited: entry.rateLimited,
+		}
+		if entry.fromFile {
+			u.Source = "file"
+		}
+		if !entry.lastUsed.IsZero() {
+			lastUsed := entry.lastUsed
+			u.LastUsed = &lastUsed
+		}
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].ID < usage[j].ID
+	})
+	return usage
+}
+
+// revoke disables the key with the given ID. Revoking a key from the keys
+// file rewrites the file so the revocation survives a restart; keys from the
+// main configuration stay revoked until the server restarts.
+func (ks *keyStore) revoke(id string) (bool, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	entry, ok := ks.byID[id]
+	if !ok {
+		return false, nil
+	}
+	entry.Revoked = true
+	if !entry.fromFile {
+		return true, nil
+	}
+
+	var fileKeys []config.APIKey
+	for _, e := range ks.byID {
+		if e.fromFile {
+			fileKeys = append(fileKeys, e.APIKey)
+		}
+	}
+	sort.Slice(fileKeys, func(i, j int) bool {
+		return fileKeys[i].ID < fileKeys[j].ID
+	})
+	data, err := yaml.Marshal(fileKeys)
+	if err != nil {
+		return true, err
+	}
+	return true, os.WriteFile(ks.keysFile, data, 0o600)
+}
+
+// requestKey returns the API key sent in the X-API-Key header or as a
+// bearer token.
+func requestKey(c *fiber.Ctx) string {
+	if key := c.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if auth := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return ""
+}
+
+// authenticate resolves the API key of the request. A key that is sent must
+// be valid; requests without one are only let through when keys are not
+// required.
+func (s *Server) authenticate(c *fiber.Ctx) error {
+	key := requestKey(c)
+	if key == "" {
+		if s.cfg.API.Auth.Required {
+			return errors.New(errors.CodeUnauthorized, "send an API key in the X-API-Key header")
+		}
+		return c.Next()
+	}
+
+	entry, ok := s.keys.lookup(key)
+	if !ok {
+		return errors.New(errors.CodeUnauthorized, "the API key is not valid")
+	}
+	c.Locals(apiKeyKey, entry)
+	return c.Next()
+}
+
+// requireAdmin only lets requests with an admin key through.
+func (s *Server) requireAdmin(c *fiber.Ctx) error {
+	entry, ok := s.keys.lookup(requestKey(c))
+	if !ok {
+		return errors.New(errors.CodeUnauthorized, "send an admin API key in the X-API-Key header")
+	}
+	if !entry.Admin {
+		return errors.New(errors.CodeForbidden, "the API key is not an admin key")
+	}
+	c.Locals(apiKeyKey, entry)
+	return c.Next()
+}
+
+func (s *Server) listKeys(c *fiber.Ctx) error {
+	return c.JSON(s.keys.list())
+}
+
+func (s *Server) revokeKey(c *fiber.Ctx) error {
+	id := c.Params("id")
+	found, err := s.keys.revoke(id)
+	if !found {
+		return fiber.NewError(fiber.StatusNotFound, "no API key with id "+id)
+	}
+	if err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
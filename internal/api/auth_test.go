@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jvanrhyn/woordsoek/internal/config"
+)
+
+const searchURL = "/search?singleLetter=o&sixCharString=aedr"
+
+func request(t *testing.T, app *fiber.App, method, url, key string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, url, nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test(%s %s) returned an error: %v", method, url, err)
+	}
+
+	rec := httptest.NewRecorder()
+	rec.Code = resp.StatusCode
+	for name, values := range resp.Header {
+		rec.Header()[name] = values
+	}
+	_, _ = rec.Body.ReadFrom(resp.Body)
+	return rec
+}
+
+func TestAuthentication(t *testing.T) {
+	keysFile := filepath.Join(t.TempDir(), "keys.yaml")
+	content := "- id: client\n  hash: " + HashKey("client-key") + "\n"
+	if err := os.WriteFile(keysFile, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write keys file: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.API.Auth = config.AuthConfig{
+		Required: true,
+		KeysFile: keysFile,
+		Keys:     []config.APIKey{{ID: "ops", Hash: HashKey("admin-key"), Admin: true}},
+	}
+	app := newTestServer(t, cfg).App()
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		key    string
+		status int
+	}{
+		{"missing key", "GET", searchURL, "", 401},
+		{"unknown key", "GET", searchURL, "nope", 401},
+		{"file key", "GET", searchURL, "client-key", 200},
+		{"bearer-less admin", "GET", "/admin/keys", "", 401},
+		{"non-admin key", "GET", "/admin/keys", "client-key", 403},
+		{"revoke unknown", "DELETE", "/admin/keys/missing", "admin-key", 404},
+		{"revoke", "DELETE", "/admin/keys/client", "admin-key", 204},
+		{"revoked key", "GET", searchURL, "client-key", 401},
+	}
+	for _, test := range tests {
+		if rec := request(t, app, test.method, test.url, test.key); rec.Code != test.status {
+			t.Errorf("%s: %s %s returned %d; expected %d", test.name, test.method, test.url, rec.Code, test.status)
+		}
+	}
+
+	rec := request(t, app, "GET", "/admin/keys", "admin-key")
+	var keys []KeyUsage
+	if err := json.Unmarshal(rec.Body.Bytes(), &keys); err != nil {
+		t.Fatalf("GET /admin/keys returned an undecodable body: %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "client" || !keys[0].Revoked || keys[0].Requests != 1 || keys[0].Source != "file" {
+		t.Errorf("GET /admin/keys returned %+v; expected the revoked client key with one request first", keys)
+	}
+	if strings.Contains(rec.Body.String(), HashKey("client-key")) {
+		t.Error("GET /admin/keys exposes key hashes")
+	}
+
+	// The revocation is persisted to the keys file
+	data, _ := os.ReadFile(keysFile)
+	if !strings.Contains(string(data), "revoked: true") {
+		t.Errorf("keys file after revoking is %q; expected the key to be marked revoked", data)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	cfg := config.Default()
+	cfg.API.Auth.Keys = []config.APIKey{{ID: "client", Hash: HashKey("client-key")}}
+	cfg.API.RateLimit.PerIP = config.RateLimit{RequestsPerMinute: 1, Burst: 2}
+	cfg.API.RateLimit.PerKey = config.RateLimit{RequestsPerMinute: 60, Burst: 5}
+	app := newTestServer(t, cfg).App()
+
+	for i, expected := range []struct {
+		status    int
+		remaining string
+	}{{200, "1"}, {200, "0"}, {429, "0"}} {
+		rec := request(t, app, "GET", searchURL, "")
+		if rec.Code != expected.status || rec.Header().Get("RateLimit-Remaining") != expected.remaining {
+			t.Errorf("anonymous request %d returned %d with RateLimit-Remaining %q; expected %d and %q",
+				i+1, rec.Code, rec.Header().Get("RateLimit-Remaining"), expected.status, expected.remaining)
+		}
+		if rec.Header().Get("RateLimit-Limit") != "2" {
+			t.Errorf("anonymous request %d returned RateLimit-Limit %q; expected 2", i+1, rec.Header().Get("RateLimit-Limit"))
+		}
+		if expected.status == 429 && rec.Header().Get("Retry-After") == "" {
+			t.Error("rate limited request has no Retry-After header")
+		}
+	}
+
+	// Requests with a key use the per-key bucket instead
+	rec := request(t, app, "GET", searchURL, "client-key")
+	if rec.Code != 200 || rec.Header().Get("RateLimit-Limit") != "5" || rec.Header().Get("RateLimit-Remaining") != "4" {
+		t.Errorf("keyed request returned %d with headers %v; expected 200 from the per-key bucket", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+// BatchQuery is one search in a batch. An empty Locale falls back to the
+// x-locale header of the request and then to api.default_locale.
+type BatchQuery struct {
+	Locale        string `json:"locale,omitempty"`
+	SingleLetter  string `json:"singleLetter"`
+	SixCharString string `json:"sixCharString"`
+	Length        int    `json:"length,omitempty"`
+}
+
+// BatchResult is the outcome of one query in a batch. Either Error is set or
+// the remaining fields hold the results, as in SearchResponse.
+type BatchResult struct {
+	Query             BatchQuery `json:"query"`
+	DictionaryVersion string     `json:"dictionaryVersion,omitempty"`
+	Count             int        `json:"count"`
+	Results           []string   `json:"results"`
+	Error             *Problem   `json:"error,omitempty"`
+}
+
+// BatchResponse is returned by /v1/search/batch, with one result per query
+// in the order the queries were sent.
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// searchBatch runs a JSON array of queries concurrently on at most
+// api.batch.workers goroutines. A failing query does not fail the batch; its
+// problem is reported in its own result instead.
+func (s *Server) searchBatch(c *fiber.Ctx) error {
+	var queries []BatchQuery
+	if err := json.Unmarshal(c.Body(), &queries); err != nil {
+		return errors.Wrap(errors.CodeInvalidQuery, "the body must be a JSON array of queries", err)
+	}
+	if len(queries) == 0 {
+		return errors.New(errors.CodeInvalidQuery, "the batch contains no queries")
+	}
+	if max := s.cfg.API.Batch.MaxQueries; len(queries) > max {
+		return errors.New(errors.CodeInvalidQuery, fmt.Sprintf("a batch may contain at most %d queries", max))
+	}
+
+	defaultLocale := c.Get("x-locale")
+	if defaultLocale == "" {
+		defaultLocale = s.cfg.API.DefaultLocale
+	}
+
+	ctx := c.UserContext()
+	results := make([]BatchResult, len(queries))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(s.cfg.API.Batch.Workers, len(queries)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				q := queries[i]
+				if q.Locale == "" {
+					q.Locale = defaultLocale
+				}
+				results[i] = BatchResult{Query: q, Results: []string{}}
+
+				result, err := s.engine.Search(ctx, woordsoek.Query{
+					Locale:        q.Locale,
+					SingleLetter:  q.SingleLetter,
+					SixCharString: q.SixCharString,
+					Length:        q.Length,
+				})
+				if err != nil {
+					problem := NewProblem(err, "")
+					results[i].Error = &problem
+					continue
+				}
+				results[i].DictionaryVersion = result.Version
+				results[i].Count = len(result.Words)
+				if result.Words != nil {
+					results[i].Results = result.Words
+				}
+			}
+		}()
+	}
+	for i := range queries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	slog.InfoContext(ctx, "Searched batch", "queries", len(queries))
+	return c.JSON(BatchResponse{Results: results})
+}
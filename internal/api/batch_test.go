@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+)
+
+func TestSearchBatch(t *testing.T) {
+	cfg := config.Default()
+	cfg.API.Batch = config.BatchConfig{MaxQueries: 4, Workers: 2}
+	app := newTestServer(t, cfg).App()
+
+	body := `[
+		{"singleLetter": "o", "sixCharString": "aedr"},
+		{"locale": "missing", "singleLetter": "o", "sixCharString": "aedr"},
+		{"singleLetter": "", "sixCharString": "aedr"},
+		{"locale": "test", "singleLetter": "d", "sixCharString": "aero", "length": 4}
+	]`
+	req := httptest.NewRequest("POST", "/v1/search/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test returned an error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("POST /v1/search/batch returned status %d; expected 200", resp.StatusCode)
+	}
+
+	var response BatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("POST /v1/search/batch returned an undecodable body: %v", err)
+	}
+	if len(response.Results) != 4 {
+		t.Fatalf("POST /v1/search/batch returned %d results; expected 4", len(response.Results))
+	}
+
+	tests := []struct {
+		locale   string
+		expected []string
+		code     errors.Code
+	}{
+		{"test", []string{"adore", "door", "odor", "road", "rode"}, ""},
+		{"missing", []string{}, errors.CodeDictionaryNotFound},
+		{"test", []string{}, errors.CodeInvalidQuery},
+		{"test", []string{"dare", "door", "odor", "road", "rode"}, ""},
+	}
+	for i, test := range tests {
+		result := response.Results[i]
+		if result.Query.Locale != test.locale || !reflect.DeepEqual(result.Results, test.expected) || result.Count != len(test.expected) {
+			t.Errorf("Batch result %d = %+v; expected %v from locale %s", i, result, test.expected, test.locale)
+		}
+		if test.code == "" && (result.Error != nil || result.DictionaryVersion == "") {
+			t.Errorf("Batch result %d has error %+v and version %q; expected a version and no error", i, result.Error, result.DictionaryVersion)
+		}
+		if test.code != "" && (result.Error == nil || result.Error.Code != test.code) {
+			t.Errorf("Batch result %d has error %+v; expected code %s", i, result.Error, test.code)
+		}
+	}
+}
+
+func TestSearchBatchProblems(t *testing.T) {
+	cfg := config.Default()
+	cfg.API.Batch = config.BatchConfig{MaxQueries: 2, Workers: 2}
+	app := newTestServer(t, cfg).App()
+
+	for _, body := range []string{
+		`{"singleLetter": "o"}`,
+		`[]`,
+		`[{"singleLetter": "o"}, {"singleLetter": "a"}, {"singleLetter": "d"}]`,
+	} {
+		resp, err := app.Test(httptest.NewRequest("POST", "/v1/search/batch", strings.NewReader(body)))
+		if err != nil {
+			t.Fatalf("app.Test returned an error: %v", err)
+		}
+		var problem Problem
+		if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+			t.Fatalf("POST /v1/search/batch returned an undecodable body: %v", err)
+		}
+		if resp.StatusCode != 400 || problem.Code != errors.CodeInvalidQuery {
+			t.Errorf("POST /v1/search/batch with %s returned %d %+v; expected 400 %s", body, resp.StatusCode, problem, errors.CodeInvalidQuery)
+		}
+	}
+}
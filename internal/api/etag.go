@@ -0,0 +1,59 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jvanrhyn/woordsoek/internal/render"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+// searchETag returns the entity tag of a page of search results in format f.
+// Group names the grouping of the results, if any. The tag is weak because
+// queries with the same normalized key share it even though the parameters
+// they echo differ. It changes whenever the dictionary version does.
+func searchETag(version string, q woordsoek.Query, p woordsoek.PageRequest, f render.Format, group string) string {
+	// Only JSON responses are grouped
+	if f != render.JSON {
+		group = ""
+	}
+	key := q.Key() + "|" + string(p.Sort) + "|" + strconv.Itoa(p.Limit) + "|" + p.Cursor + "|" + string(f) + "|" + group
+	sum := sha256.Sum256([]byte(key))
+	return `W/"` + version + "-" + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// etagMatches reports whether the If-None-Match header matches etag, using
+// the weak comparison required for conditional GETs.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// setCacheHeaders marks a search response as cacheable for maxAge. The
+// locale and format come from headers, so caches must key on them too.
+// Responses are private when API keys are required, so that shared caches do
+// not hand them to clients without one.
+func (s *Server) setCacheHeaders(c *fiber.Ctx, etag string) {
+	c.Set(fiber.HeaderETag, etag)
+	c.Vary("X-Locale", fiber.HeaderAccept)
+
+	maxAge := time.Duration(s.cfg.API.CacheMaxAge)
+	if maxAge <= 0 {
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		return
+	}
+	scope := "public"
+	if s.cfg.API.Auth.Required {
+		scope = "private"
+	}
+	c.Set(fiber.HeaderCacheControl, scope+", max-age="+strconv.Itoa(int(maxAge.Seconds())))
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProbeResponse is returned by /healthz and /readyz.
+type ProbeResponse struct {
+	Status  string          `json:"status"`
+	Locales map[string]bool `json:"locales,omitempty"`
+}
+
+// healthz reports that the process is alive and serving requests.
+func (s *Server) healthz(c *fiber.Ctx) error {
+	return c.JSON(ProbeResponse{Status: "ok"})
+}
+
+// readyz reports whether the preloaded locales are in memory. It reports
+// unavailable while they are loading and once shutdown has started.
+func (s *Server) readyz(c *fiber.Ctx) error {
+	locales, err := s.preloadLocales()
+	if err != nil {
+		return err
+	}
+
+	response := ProbeResponse{Status: "ready", Locales: make(map[string]bool, len(locales))}
+	ready := !s.draining.Load()
+	for _, locale := range locales {
+		loaded := s.engine.Loaded(locale)
+		response.Locales[locale] = loaded
+		ready = ready && loaded
+	}
+
+	if !ready {
+		response.Status = "not ready"
+		if s.draining.Load() {
+			response.Status = "shutting down"
+		}
+		return c.Status(fiber.StatusServiceUnavailable).JSON(response)
+	}
+	return c.JSON(response)
+}
+
+// preloadLocales expands api.preload, where "*" means every dictionary.
+func (s *Server) preloadLocales() ([]string, error) {
+	for _, locale := range s.cfg.API.Preload {
+		if locale == "*" {
+			return s.engine.Locales()
+		}
+	}
+	return s.cfg.API.Preload, nil
+}
+
+// preload loads the configured locales so that /readyz can report ready.
+func (s *Server) preload(ctx context.Context) {
+	locales, err := s.preloadLocales()
+	if err != nil {
+		slog.Error("Failed to list locales to preload", "error", err)
+		return
+	}
+
+	for _, locale := range locales {
+		if _, err := s.engine.Index(ctx, locale); err != nil {
+			slog.Error("Failed to preload dictionary", "locale", locale, "error", err)
+			continue
+		}
+		slog.Info("Preloaded dictionary", "locale", locale)
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jvanrhyn/woordsoek/internal/config"
+)
+
+func TestProbes(t *testing.T) {
+	cfg := config.Default()
+	cfg.API.Preload = []string{"*"}
+	s := newTestServer(t, cfg)
+
+	probe := func(path string) (int, ProbeResponse) {
+		t.Helper()
+		resp, err := s.App().Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatalf("app.Test(%s) returned an error: %v", path, err)
+		}
+		var response ProbeResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("GET %s returned an undecodable body: %v", path, err)
+		}
+		return resp.StatusCode, response
+	}
+
+	if status, _ := probe("/healthz"); status != 200 {
+		t.Errorf("GET /healthz returned status %d; expected 200", status)
+	}
+
+	if status, response := probe("/readyz"); status != 503 || response.Locales["test"] {
+		t.Errorf("GET /readyz before preloading returned %d %+v; expected 503", status, response)
+	}
+
+	s.preload(context.Background())
+	if status, response := probe("/readyz"); status != 200 || !response.Locales["test"] {
+		t.Errorf("GET /readyz after preloading returned %d %+v; expected 200", status, response)
+	}
+
+	s.draining.Store(true)
+	if status, response := probe("/readyz"); status != 503 || response.Status != "shutting down" {
+		t.Errorf("GET /readyz while draining returned %d %+v; expected 503", status, response)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jvanrhyn/woordsoek/internal/render"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+// HintsResponse is returned by /v1/search/hints. It describes the results of
+// a search without listing them, so that players can ask for a nudge
+// without seeing the answers.
+type HintsResponse struct {
+	Parameters        map[string]string `json:"parameters"`
+	DictionaryVersion string            `json:"dictionaryVersion"`
+	Hints             woordsoek.Hints   `json:"hints"`
+}
+
+// hints counts the results of a search by first letter, length and two
+// letter prefix. It takes the same query as /search, without paging.
+func (s *Server) hints(c *fiber.Ctx) error {
+	q, err := s.parseQuery(c)
+	if err != nil {
+		return err
+	}
+	result, err := s.engine.Search(c.UserContext(), q)
+	if err != nil {
+		return err
+	}
+	c.Locals(localeKey, q.Locale)
+
+	c.Set(dictionaryVersionHeader, result.Version)
+	etag := searchETag(result.Version, q, woordsoek.PageRequest{}, render.JSON, "hints")
+	s.setCacheHeaders(c, etag)
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	slog.InfoContext(c.UserContext(), "Counted hints", "wordcount", len(result.Words), "cached", result.Cached)
+	return c.JSON(HintsResponse{
+		Parameters: map[string]string{
+			"singleLetter":  q.SingleLetter,
+			"sixCharString": q.SixCharString,
+			"length":        c.Query("length"),
+		},
+		DictionaryVersion: result.Version,
+		Hints:             woordsoek.NewHints(result.Words, q),
+	})
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+func TestHints(t *testing.T) {
+	app := newTestApp(t)
+
+	rec := request(t, app, "GET", "/v1/search/hints?singleLetter=o&sixCharString=aedr", "")
+	if rec.Code != 200 {
+		t.Fatalf("GET /v1/search/hints returned status %d; expected 200", rec.Code)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "adore") {
+		t.Errorf("GET /v1/search/hints gave the answers away: %s", body)
+	}
+
+	var response HintsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("GET /v1/search/hints returned an undecodable body: %v", err)
+	}
+	expected := woordsoek.Hints{
+		Words:        5,
+		Points:       12 + 4,
+		Pangrams:     1,
+		Lengths:      []int{4, 5},
+		LengthTotals: []int{4, 1},
+		Grid: []woordsoek.HintRow{
+			{Letter: "a", Counts: []int{0, 1}, Total: 1},
+			{Letter: "d", Counts: []int{1, 0}, Total: 1},
+			{Letter: "o", Counts: []int{1, 0}, Total: 1},
+			{Letter: "r", Counts: []int{2, 0}, Total: 2},
+		},
+		Prefixes: []woordsoek.PrefixCount{
+			{Prefix: "ad", Count: 1}, {Prefix: "do", Count: 1}, {Prefix: "od", Count: 1}, {Prefix: "ro", Count: 2},
+		},
+	}
+	if !reflect.DeepEqual(response.Hints, expected) {
+		t.Errorf("GET /v1/search/hints returned %+v; expected %+v", response.Hints, expected)
+	}
+
+	if rec := request(t, app, "GET", "/v1/search/hints?sixCharString=aedr", ""); rec.Code != 400 {
+		t.Errorf("GET /v1/search/hints without a single letter returned %d; expected 400", rec.Code)
+	}
+}
+
+func TestSearchGroups(t *testing.T) {
+	app := newTestApp(t)
+
+	rec := request(t, app, "GET", "/search?singleLetter=o&sixCharString=aedr&group=length", "")
+	var response SearchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("GET /search returned an undecodable body: %v", err)
+	}
+	expected := []woordsoek.Group{
+		{Length: 4, Words: []string{"door", "odor", "road", "rode"}},
+		{Length: 5, Words: []string{"adore"}},
+	}
+	if !reflect.DeepEqual(response.Groups, expected) {
+		t.Errorf("GET /search?group=length returned groups %+v; expected %+v", response.Groups, expected)
+	}
+
+	plain := request(t, app, "GET", "/search?singleLetter=o&sixCharString=aedr", "")
+	if plain.Header().Get("ETag") == rec.Header().Get("ETag") {
+		t.Error("Grouped and ungrouped results share an ETag")
+	}
+	// Other formats are never grouped, so group does not change them
+	csv := request(t, app, "GET", "/search?singleLetter=o&sixCharString=aedr&format=csv", "")
+	groupedCSV := request(t, app, "GET", "/search?singleLetter=o&sixCharString=aedr&format=csv&group=length", "")
+	if csv.Header().Get("ETag") != groupedCSV.Header().Get("ETag") {
+		t.Error("CSV results asked to be grouped have another ETag")
+	}
+
+	if rec := request(t, app, "GET", "/search?singleLetter=o&sixCharString=aedr&group=letter", ""); rec.Code != 400 {
+		t.Errorf("GET /search?group=letter returned %d; expected 400", rec.Code)
+	}
+}
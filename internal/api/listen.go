@@ -0,0 +1,318 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"golang.org/x/net/http2"
+)
+
+// listen opens the listener for api.addr, serving HTTPS when api.tls is set.
+// Fiber runs on fasthttp, which only speaks HTTP/1.1, so with api.http2 the
+// HTTP/2 connections (negotiated with ALPN over TLS, or opened with the
+// HTTP/2 preface in plain text) are split off and served by net/http through
+// an adaptor. All other connections, including WebSocket upgrades, are
+// returned by the listener to Fiber.
+func (s *Server) listen() (net.Listener, error) {
+	var tlsConfig *tls.Config
+	if s.cfg.API.TLS.Enabled() {
+		var err error
+		if tlsConfig, err = loadTLSConfig(s.cfg.API.TLS); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen("tcp", s.cfg.API.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if !s.cfg.API.HTTP2 {
+		if tlsConfig != nil {
+			return tls.NewListener(ln, tlsConfig), nil
+		}
+		return ln, nil
+	}
+
+	if tlsConfig != nil {
+		tlsConfig.NextProtos = []string{http2.NextProtoTLS, "http/1.1"}
+	}
+	if s.h2, err = newH2Server(s.app, s.cfg.API); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return newProtocolListener(ln, tlsConfig, s.h2.serveConn, time.Duration(s.cfg.API.ReadTimeout)), nil
+}
+
+// loadTLSConfig loads the configured certificate or generates a self-signed
+// one.
+func loadTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	var (
+		cert tls.Certificate
+		err  error
+	)
+	if cfg.SelfSigned {
+		slog.Warn("Serving HTTPS with a self-signed certificate, which is only fit for development")
+		cert, err = selfSignedCertificate(time.Now())
+	} else {
+		cert, err = tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// selfSignedCertificate creates a certificate for localhost that is valid
+// for 30 days from now. Its key only lives in memory.
+func selfSignedCertificate(now time.Time) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "woordsoek development"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(30 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// h2Server serves HTTP/2 connections with the Fiber app.
+type h2Server struct {
+	base *http.Server
+	h2   *http2.Server
+
+	mu      sync.Mutex
+	closing bool
+	conns   sync.WaitGroup
+}
+
+func newH2Server(app *fiber.App, cfg config.APIConfig) (*h2Server, error) {
+	base := &http.Server{
+		Handler:      limitBody(adaptor.FiberApp(app), cfg.BodyLimit),
+		ReadTimeout:  time.Duration(cfg.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.IdleTimeout),
+		ErrorLog:     slog.NewLogLogger(slog.Default().Handler(), slog.LevelDebug),
+	}
+	h2 := &http2.Server{IdleTimeout: time.Duration(cfg.IdleTimeout)}
+	if err := http2.ConfigureServer(base, h2); err != nil {
+		return nil, err
+	}
+	return &h2Server{base: base, h2: h2}, nil
+}
+
+// serveConn serves conn until the client closes it or shutdown completes.
+func (h *h2Server) serveConn(conn net.Conn) {
+	h.mu.Lock()
+	if h.closing {
+		h.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	h.conns.Add(1)
+	h.mu.Unlock()
+	defer h.conns.Done()
+
+	h.h2.ServeConn(conn, &http2.ServeConnOpts{BaseConfig: h.base, Handler: h.base.Handler})
+}
+
+// shutdown tells HTTP/2 clients to go away and waits until their open
+// streams complete or ctx is done.
+func (h *h2Server) shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closing = true
+	h.mu.Unlock()
+
+	if err := h.base.Shutdown(ctx); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	go func() {
+		h.conns.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limitBody rejects request bodies of more than limit bytes, as fasthttp
+// does for HTTP/1.1 requests.
+func limitBody(next http.Handler, limit int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(limit)))
+		if err != nil {
+			status := http.StatusBadRequest
+			if _, ok := err.(*http.MaxBytesError); ok {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// protocolListener accepts connections from a net.Listener, hands the ones
+// speaking HTTP/2 to serveH2 and returns the others from Accept.
+type protocolListener struct {
+	net.Listener
+	tlsConfig *tls.Config
+	serveH2   func(net.Conn)
+	// timeout bounds the TLS handshake or reading the HTTP/2 preface
+	timeout time.Duration
+
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+func newProtocolListener(ln net.Listener, tlsConfig *tls.Config, serveH2 func(net.Conn), timeout time.Duration) *protocolListener {
+	l := &protocolListener{
+		Listener:  ln,
+		tlsConfig: tlsConfig,
+		serveH2:   serveH2,
+		timeout:   timeout,
+		conns:     make(chan net.Conn),
+		done:      make(chan struct{}),
+	}
+	go l.acceptLoop()
+	return l
+}
+
+// Accept returns the next HTTP/1.1 connection.
+func (l *protocolListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, l.err
+	}
+}
+
+// Close stops accepting connections. Connections already handed out are not
+// closed.
+func (l *protocolListener) Close() error {
+	l.stop(net.ErrClosed)
+	return l.Listener.Close()
+}
+
+func (l *protocolListener) stop(err error) {
+	l.closeOnce.Do(func() {
+		l.err = err
+		close(l.done)
+	})
+}
+
+func (l *protocolListener) acceptLoop() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			l.stop(err)
+			return
+		}
+		go l.route(conn)
+	}
+}
+
+// route sends conn to the HTTP/2 server or to Accept.
+func (l *protocolListener) route(conn net.Conn) {
+	if l.timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(l.timeout))
+	}
+	isH2, conn, err := l.sniff(conn)
+	if err != nil {
+		slog.Debug("Dropped connection before its first request", "remote", conn.RemoteAddr(), "error", err)
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	if isH2 {
+		l.serveH2(conn)
+		return
+	}
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		_ = conn.Close()
+	}
+}
+
+// sniff reports whether conn speaks HTTP/2, completing the TLS handshake
+// first when serving TLS. The returned connection replaces conn.
+func (l *protocolListener) sniff(conn net.Conn) (bool, net.Conn, error) {
+	if l.tlsConfig != nil {
+		tlsConn := tls.Server(conn, l.tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			return false, tlsConn, err
+		}
+		return tlsConn.ConnectionState().NegotiatedProtocol == http2.NextProtoTLS, tlsConn, nil
+	}
+
+	// Plain text HTTP/2 clients open with a fixed preface, which no
+	// HTTP/1.1 request starts with; stop reading at the first difference
+	peeked := &peekedConn{Conn: conn, r: bufio.NewReaderSize(conn, len(http2.ClientPreface))}
+	for n := 1; n <= len(http2.ClientPreface); n++ {
+		b, err := peeked.r.Peek(n)
+		if !strings.HasPrefix(http2.ClientPreface, string(b)) {
+			return false, peeked, nil
+		}
+		if err != nil {
+			return false, peeked, err
+		}
+	}
+	return true, peeked, nil
+}
+
+// peekedConn is a connection whose first bytes were read ahead into r.
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *peekedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"golang.org/x/net/http2"
+)
+
+// serve starts s on a free port through the same listener as Run and
+// returns its address.
+func serve(t *testing.T, s *Server) string {
+	t.Helper()
+	s.cfg.API.Addr = "127.0.0.1:0"
+	ln, err := s.listen()
+	if err != nil {
+		t.Fatalf("listen returned an error: %v", err)
+	}
+	go func() { _ = s.app.Listener(ln) }()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = s.app.ShutdownWithContext(ctx)
+		if s.h2 != nil {
+			_ = s.h2.shutdown(ctx)
+		}
+	})
+	return ln.Addr().String()
+}
+
+// The clients trust the self-signed certificate. Each has its own TLS config
+// as transports add the protocols they speak to it.
+var (
+	http1Client = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		// A non-nil empty map turns off HTTP/2
+		TLSNextProto: map[string]func(string, *tls.Conn) http.RoundTripper{},
+	}
+	http2Client = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, ForceAttemptHTTP2: true}
+	h2cClient   = &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+)
+
+func TestListenProtocols(t *testing.T) {
+	tests := []struct {
+		name     string
+		tls      bool
+		http2    bool
+		client   http.RoundTripper
+		expected string
+	}{
+		{"plain HTTP/1.1", false, false, http1Client, "HTTP/1.1"},
+		{"plain HTTP/1.1 with HTTP/2 enabled", false, true, http1Client, "HTTP/1.1"},
+		{"h2c", false, true, h2cClient, "HTTP/2.0"},
+		{"TLS without HTTP/2", true, false, http2Client, "HTTP/1.1"},
+		{"TLS HTTP/1.1 with HTTP/2 enabled", true, true, http1Client, "HTTP/1.1"},
+		{"TLS HTTP/2", true, true, http2Client, "HTTP/2.0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.API.TLS.SelfSigned = test.tls
+			cfg.API.HTTP2 = test.http2
+			addr := serve(t, newTestServer(t, cfg))
+
+			scheme := "http://"
+			if test.tls {
+				scheme = "https://"
+			}
+			client := &http.Client{Transport: test.client, Timeout: 5 * time.Second}
+			resp, err := client.Get(scheme + addr + "/search?singleLetter=o&sixCharString=aedr")
+			if err != nil {
+				t.Fatalf("GET /search returned an error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var response SearchResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil || resp.StatusCode != 200 || response.Count != 5 {
+				t.Errorf("GET /search returned status %d with %+v, %v; expected 200 with 5 results", resp.StatusCode, response, err)
+			}
+			if resp.Proto != test.expected {
+				t.Errorf("GET /search was served over %s; expected %s", resp.Proto, test.expected)
+			}
+		})
+	}
+}
+
+func TestListenHTTP2KeepsWebSockets(t *testing.T) {
+	cfg := config.Default()
+	cfg.API.HTTP2 = true
+	addr := serve(t, newTestServer(t, cfg))
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/ws/search", nil)
+	if err != nil {
+		t.Fatalf("Failed to open a live search with HTTP/2 enabled: %v", err)
+	}
+	_ = conn.Close()
+}
+
+func TestBodyLimit(t *testing.T) {
+	cfg := config.Default()
+	cfg.API.HTTP2 = true
+	cfg.API.BodyLimit = 64
+	addr := serve(t, newTestServer(t, cfg))
+
+	small := `[{"singleLetter": "o", "sixCharString": "aedr"}]`
+	large := "[" + strings.Repeat(small[1:len(small)-1]+",", 4) + small[1:]
+	tests := []struct {
+		name     string
+		client   http.RoundTripper
+		body     string
+		expected int
+	}{
+		{"HTTP/1.1 small", http1Client, small, 200},
+		{"HTTP/1.1 large", http1Client, large, 413},
+		{"HTTP/2 small", h2cClient, small, 200},
+		{"HTTP/2 large", h2cClient, large, 413},
+	}
+	for _, test := range tests {
+		client := &http.Client{Transport: test.client, Timeout: 5 * time.Second}
+		resp, err := client.Post("http://"+addr+"/v1/search/batch", "application/json", strings.NewReader(test.body))
+		if err != nil {
+			t.Errorf("%s: POST /v1/search/batch returned an error: %v", test.name, err)
+			continue
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != test.expected {
+			t.Errorf("%s: POST /v1/search/batch returned status %d; expected %d", test.name, resp.StatusCode, test.expected)
+		}
+	}
+}
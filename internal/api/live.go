@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanContextKey is the Fiber local holding the span context of the upgrade
+// request, so that the searches of a live connection join its trace.
+const spanContextKey = "spanContext"
+
+// LiveQuery is a message from a live search client: the query as typed so
+// far. Every message replaces the previous one. ID is echoed in the reply so
+// that clients can tell which query it answers. An empty Locale uses the
+// x-locale header of the upgrade request.
+type LiveQuery struct {
+	ID            int64  `json:"id"`
+	Locale        string `json:"locale,omitempty"`
+	SingleLetter  string `json:"singleLetter"`
+	SixCharString string `json:"sixCharString"`
+	Length        int    `json:"length,omitempty"`
+}
+
+// LiveUpdate is a message to a live search client. For a "diff", Added and
+// Removed turn the results last sent into the results of query ID, which
+// number Count. For an "error", Error says what was wrong with the query and
+// the previous results still stand.
+type LiveUpdate struct {
+	ID                int64    `json:"id"`
+	Type              string   `json:"type"`
+	DictionaryVersion string   `json:"dictionaryVersion,omitempty"`
+	Count             int      `json:"count"`
+	Added             []string `json:"added,omitempty"`
+	Removed           []string `json:"removed,omitempty"`
+	Error             *Problem `json:"error,omitempty"`
+}
+
+// liveOutcome is the result of the search for a query, tagged with the
+// sequence number of the update that started it.
+type liveOutcome struct {
+	seq    int64
+	query  LiveQuery
+	result woordsoek.Result
+	err    error
+}
+
+// upgradeLive only lets WebSocket upgrades through to the live search.
+func upgradeLive(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+	c.Locals(spanContextKey, trace.SpanContextFromContext(c.UserContext()))
+	return c.Next()
+}
+
+// liveSearch serves a live search connection. Queries are debounced by
+// api.live_debounce, and a new query cancels the search of the previous one,
+// so a client typing quickly only gets results for what it typed last.
+func (s *Server) liveSearch(conn *websocket.Conn) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if sc, ok := conn.Locals(spanContextKey).(trace.SpanContext); ok {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+	}
+
+	defaultLocale := conn.Headers("X-Locale", s.cfg.API.DefaultLocale)
+	debounce := time.Duration(s.cfg.API.LiveDebounce)
+
+	updates := make(chan LiveQuery)
+	go func() {
+		defer close(updates)
+		for {
+			var q LiveQuery
+			if err := conn.ReadJSON(&q); err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					slog.DebugContext(ctx, "Live search connection closed", "error", err)
+				}
+				return
+			}
+			select {
+			case updates <- q:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		seq     int64 // of the latest query
+		pending LiveQuery
+		sent    []string
+	)
+	outcomes := make(chan liveOutcome)
+	cancelSearch := context.CancelFunc(func() {})
+	defer func() { cancelSearch() }()
+
+	// search starts the search of the pending query.
+	search := func() {
+		q := pending
+		if q.Locale == "" {
+			q.Locale = defaultLocale
+		}
+		searchCtx, stop := context.WithCancel(ctx)
+		cancelSearch = stop
+		go func(seq int64) {
+			outcome := liveOutcome{seq: seq, query: q}
+			if q.SingleLetter != "" {
+				outcome.result, outcome.err = s.engine.Search(searchCtx, woordsoek.Query{
+					Locale:        q.Locale,
+					SingleLetter:  q.SingleLetter,
+					SixCharString: q.SixCharString,
+					Length:        q.Length,
+				})
+			}
+			select {
+			case outcomes <- outcome:
+			case <-searchCtx.Done():
+			}
+		}(seq)
+	}
+
+	// Without a debounce the timer is never started; a timer of 0 could still
+	// fire after being stopped and search again.
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	for {
+		select {
+		case q, ok := <-updates:
+			if !ok {
+				return
+			}
+			seq++
+			pending = q
+			cancelSearch()
+			if debounce <= 0 {
+				search()
+				continue
+			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(debounce)
+
+		case <-timer.C:
+			search()
+
+		case outcome := <-outcomes:
+			// Results of a query that has since been replaced are dropped
+			if outcome.seq != seq {
+				continue
+			}
+			update := LiveUpdate{ID: outcome.query.ID, Type: "diff"}
+			if outcome.err != nil {
+				if errors.CodeOf(outcome.err) == errors.CodeCancelled {
+					continue
+				}
+				problem := NewProblem(outcome.err, "")
+				update.Type, update.Error, update.Count = "error", &problem, len(sent)
+			} else {
+				words := outcome.result.Words
+				update.DictionaryVersion = outcome.result.Version
+				update.Count = len(words)
+				update.Added, update.Removed = diffSorted(sent, words)
+				sent = words
+			}
+			if err := conn.WriteJSON(update); err != nil {
+				slog.DebugContext(ctx, "Failed to write live search update", "error", err)
+				return
+			}
+		}
+	}
+}
+
+// diffSorted returns the words only in next and the words only in prev,
+// both of which must be sorted.
+func diffSorted(prev, next []string) (added, removed []string) {
+	i, j := 0, 0
+	for i < len(prev) || j < len(next) {
+		switch {
+		case j == len(next) || (i < len(prev) && prev[i] < next[j]):
+			removed = append(removed, prev[i])
+			i++
+		case i == len(prev) || next[j] < prev[i]:
+			added = append(added, next[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return added, removed
+}
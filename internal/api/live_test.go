@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+)
+
+func TestLiveSearch(t *testing.T) {
+	cfg := config.Default()
+	cfg.API.LiveDebounce = config.Duration(30 * time.Millisecond)
+	app := newTestServer(t, cfg).App()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go func() { _ = app.Listener(ln) }()
+	defer func() { _ = app.Shutdown() }()
+
+	// Plain requests are told to upgrade
+	resp, err := app.Test(httptest.NewRequest("GET", "/ws/search", nil))
+	if err != nil || resp.StatusCode != 426 {
+		t.Fatalf("GET /ws/search without an upgrade returned %v, %v; expected 426", resp.StatusCode, err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/ws/search", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	send := func(q LiveQuery) {
+		t.Helper()
+		if err := conn.WriteJSON(q); err != nil {
+			t.Fatalf("Failed to send %+v: %v", q, err)
+		}
+	}
+	receive := func() LiveUpdate {
+		t.Helper()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var update LiveUpdate
+		if err := conn.ReadJSON(&update); err != nil {
+			t.Fatalf("Failed to receive an update: %v", err)
+		}
+		return update
+	}
+
+	// A query replaced within the debounce time is never answered
+	send(LiveQuery{ID: 1, SingleLetter: "o", SixCharString: "aed"})
+	send(LiveQuery{ID: 2, SingleLetter: "o", SixCharString: "aedr"})
+
+	tests := []struct {
+		query    LiveQuery
+		expected LiveUpdate
+	}{
+		{LiveQuery{}, LiveUpdate{ID: 2, Type: "diff", Count: 5, Added: []string{"adore", "door", "odor", "road", "rode"}}},
+		{LiveQuery{ID: 3, SingleLetter: "o", SixCharString: "aedr", Length: 4}, LiveUpdate{ID: 3, Type: "diff", Count: 4, Removed: []string{"adore"}}},
+		{LiveQuery{ID: 4, Locale: "missing", SingleLetter: "o"}, LiveUpdate{ID: 4, Type: "error", Count: 4}},
+		{LiveQuery{ID: 5, SingleLetter: "d", SixCharString: "aeor", Length: 4}, LiveUpdate{ID: 5, Type: "diff", Count: 5, Added: []string{"dare"}}},
+		{LiveQuery{ID: 6}, LiveUpdate{ID: 6, Type: "diff", Count: 0, Removed: []string{"dare", "door", "odor", "road", "rode"}}},
+	}
+	for _, test := range tests {
+		if test.query.ID != 0 {
+			send(test.query)
+		}
+		update := receive()
+		if test.expected.Type == "error" {
+			if update.ID != test.expected.ID || update.Type != "error" || update.Error == nil || update.Error.Code != errors.CodeDictionaryNotFound {
+				t.Errorf("Update for %+v = %+v; expected a %s error", test.query, update, errors.CodeDictionaryNotFound)
+			}
+			continue
+		}
+		update.DictionaryVersion = ""
+		if !reflect.DeepEqual(update, test.expected) {
+			t.Errorf("Update for %+v = %+v; expected %+v", test.query, update, test.expected)
+		}
+	}
+}
+
+func TestDiffSorted(t *testing.T) {
+	tests := []struct {
+		prev, next     []string
+		added, removed []string
+	}{
+		{nil, []string{"a", "b"}, []string{"a", "b"}, nil},
+		{[]string{"a", "b"}, nil, nil, []string{"a", "b"}},
+		{[]string{"a", "c", "d"}, []string{"b", "c", "e"}, []string{"b", "e"}, []string{"a", "d"}},
+		{[]string{"a"}, []string{"a"}, nil, nil},
+	}
+	for _, test := range tests {
+		added, removed := diffSorted(test.prev, test.next)
+		if !reflect.DeepEqual(added, test.added) || !reflect.DeepEqual(removed, test.removed) {
+			t.Errorf("diffSorted(%v, %v) = %v, %v; expected %v, %v", test.prev, test.next, added, removed, test.added, test.removed)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// localeKey is the Fiber local holding the locale a request was answered
+// from. It is only set once the locale is known to exist, which keeps the
+// metric label cardinality bounded by the dictionaries on disk.
+const localeKey = "locale"
+
+// httpMetrics holds the per-route request collectors.
+type httpMetrics struct {
+	requests    *prometheus.CounterVec
+	latency     *prometheus.HistogramVec
+	keyRequests *prometheus.CounterVec
+}
+
+func newHTTPMetrics(reg prometheus.Registerer) *httpMetrics {
+	m := &httpMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "woordsoek",
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route, method, status and locale.",
+		}, []string{"route", "method", "status", "locale"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "woordsoek",
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route, method and locale.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "locale"}),
+		keyRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "woordsoek",
+			Name:      "api_key_requests_total",
+			Help:      "Requests made with an API key, by key and result (allowed or rate_limited).",
+		}, []string{"key_id", "result"}),
+	}
+
+	reg.MustRegister(m.requests, m.latency, m.keyRequests)
+	return m
+}
+
+func (m *httpMetrics) observeKey(id string, allowed bool) {
+	result := "allowed"
+	if !allowed {
+		result = "rate_limited"
+	}
+	m.keyRequests.WithLabelValues(id, result).Inc()
+}
+
+// middleware records every request. Errors are rendered before the status is
+// read so that failed requests are counted with their final status.
+func (m *httpMetrics) middleware(c *fiber.Ctx) error {
+	start := time.Now()
+
+	err := c.Next()
+	if err != nil {
+		if herr := c.App().ErrorHandler(c, err); herr != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	route := c.Route().Path
+	locale, _ := c.Locals(localeKey).(string)
+	if locale == "" {
+		locale = "none"
+	}
+	method := c.Method()
+	status := strconv.Itoa(c.Response().StatusCode())
+
+	m.requests.WithLabelValues(route, method, status, locale).Inc()
+	m.latency.WithLabelValues(route, method, locale).Observe(time.Since(start).Seconds())
+	return nil
+}
+
+// metricsHandler serves the collectors of gatherer in the Prometheus text
+// format.
+func metricsHandler(gatherer prometheus.Gatherer) fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+}
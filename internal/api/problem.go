@@ -0,0 +1,120 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+)
+
+const (
+	problemContentType = "application/problem+json"
+
+	// statusClientClosedRequest is the de facto status for requests the
+	// client abandoned before a response could be written.
+	statusClientClosedRequest = 499
+)
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Status   int         `json:"status"`
+	Detail   string      `json:"detail,omitempty"`
+	Instance string      `json:"instance,omitempty"`
+	Code     errors.Code `json:"code"`
+}
+
+// statusForCode maps an error code to an HTTP status.
+func statusForCode(code errors.Code) int {
+	switch code {
+	case errors.CodeDictionaryNotFound:
+		return fiber.StatusNotFound
+	case errors.CodeInvalidQuery:
+		return fiber.StatusBadRequest
+	case errors.CodeCancelled:
+		return statusClientClosedRequest
+	case errors.CodeUnauthorized:
+		return fiber.StatusUnauthorized
+	case errors.CodeForbidden:
+		return fiber.StatusForbidden
+	case errors.CodeRateLimited:
+		return fiber.StatusTooManyRequests
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// codeForStatus maps the status of a Fiber error, such as an unknown route or
+// an unacceptable media type, to an error code. Client errors without a code
+// of their own are reported as bad requests.
+func codeForStatus(status int) errors.Code {
+	switch status {
+	case fiber.StatusNotFound:
+		return errors.CodeNotFound
+	case fiber.StatusMethodNotAllowed:
+		return errors.CodeMethodNotAllowed
+	case fiber.StatusNotAcceptable:
+		return errors.CodeNotAcceptable
+	case fiber.StatusUpgradeRequired:
+		return errors.CodeUpgradeRequired
+	case fiber.StatusUnauthorized:
+		return errors.CodeUnauthorized
+	case fiber.StatusForbidden:
+		return errors.CodeForbidden
+	case fiber.StatusTooManyRequests:
+		return errors.CodeRateLimited
+	}
+	if status < fiber.StatusInternalServerError {
+		return errors.CodeBadRequest
+	}
+	return errors.CodeInternal
+}
+
+// NewProblem builds the problem details for err. Messages of internal errors
+// are not exposed to clients.
+func NewProblem(err error, instance string) Problem {
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(fe.Code),
+			Status:   fe.Code,
+			Detail:   fe.Message,
+			Instance: instance,
+			Code:     codeForStatus(fe.Code),
+		}
+	}
+
+	code := errors.CodeOf(err)
+	status := statusForCode(code)
+	problem := Problem{
+		Type:     "urn:woordsoek:problem:" + string(code),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: instance,
+		Code:     code,
+	}
+	if status == statusClientClosedRequest {
+		problem.Title = "Client Closed Request"
+	}
+
+	var ce *errors.CustomError
+	if code != errors.CodeInternal && errors.As(err, &ce) {
+		problem.Detail = ce.Message
+	}
+	return problem
+}
+
+// ErrorHandler renders every error returned from a handler as
+// application/problem+json.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	problem := NewProblem(err, c.OriginalURL())
+	if problem.Status >= fiber.StatusInternalServerError {
+		slog.Error("Request failed", "path", c.Path(), "error", err)
+	}
+
+	c.Set(fiber.HeaderContentType, problemContentType)
+	return c.Status(problem.Status).JSON(problem, problemContentType)
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"math"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+)
+
+// limiter is a set of token buckets, one per key (an API key ID or a client
+// IP). Buckets start full and refill continuously at rate tokens per second.
+type limiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// quota is the state of a bucket after a request, as reported in the
+// RateLimit-* headers.
+type quota struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// newLimiter returns nil when limit is disabled.
+func newLimiter(limit config.RateLimit) *limiter {
+	if limit.RequestsPerMinute <= 0 {
+		return nil
+	}
+	return &limiter{
+		rate:    float64(limit.RequestsPerMinute) / 60,
+		burst:   float64(limit.Burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// take consumes a token from the bucket for key if one is available.
+func (l *limiter) take(key string) quota {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	q := quota{limit: int(l.burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		q.allowed = true
+	} else {
+		q.retryAfter = l.duration(1 - b.tokens)
+	}
+	q.remaining = int(b.tokens)
+	q.reset = l.duration(l.burst - b.tokens)
+	return q
+}
+
+// duration returns the time needed to refill the given number of tokens.
+func (l *limiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// sweep drops buckets that have refilled completely, at most once a minute,
+// so that one-off clients do not accumulate.
+func (l *limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimit applies the per-key limit to requests authenticated with an API
+// key and the per-IP limit to anonymous requests.
+func (s *Server) rateLimit(c *fiber.Ctx) error {
+	l, bucketKey, keyID := s.ipLimiter, "ip:"+s.clientIP(c), ""
+	if key, ok := c.Locals(apiKeyKey).(*keyEntry); ok {
+		l, bucketKey, keyID = s.keyLimiter, "key:"+key.ID, key.ID
+	}
+
+	allowed := true
+	if l != nil {
+		q := l.take(bucketKey)
+		allowed = q.allowed
+		c.Set("RateLimit-Limit", strconv.Itoa(q.limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(q.remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(q.reset)))
+		if !allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(q.retryAfter)))
+		}
+	}
+
+	if keyID != "" {
+		s.keys.record(keyID, allowed)
+		s.metrics.observeKey(keyID, allowed)
+	}
+	if !allowed {
+		return errors.New(errors.CodeRateLimited, "too many requests, try again later")
+	}
+	return c.Next()
+}
+
+// clientIP returns the address of the client. Behind trusted proxies that is
+// the rightmost address in X-Forwarded-For not of a trusted proxy: each proxy
+// appends the address it was reached from, so everything to the left of that
+// was written by the client and cannot be believed.
+func (s *Server) clientIP(c *fiber.Ctx) string {
+	peer := c.IP()
+	if !s.trusted(peer) {
+		return peer
+	}
+	forwarded := c.IPs()
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		if !s.trusted(forwarded[i]) {
+			return forwarded[i]
+		}
+	}
+	if len(forwarded) > 0 {
+		return forwarded[0]
+	}
+	return peer
+}
+
+// trusted reports whether ip is the address of a trusted proxy.
+func (s *Server) trusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, proxy := range s.proxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReloadResult reports the outcome of reloading one locale.
+type ReloadResult struct {
+	Locale  string `json:"locale"`
+	Version string `json:"version,omitempty"`
+	Words   int    `json:"words,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// reload rebuilds the indexes of the locales given in the comma-separated
+// locale parameter, or of every loaded locale when it is absent. Searches are
+// served from the previous index until the new one is swapped in. The
+// response lists the result per locale; it is a 207 when some failed. Only
+// admins can call it, so errors are reported in full.
+func (s *Server) reload(c *fiber.Ctx) error {
+	var locales []string
+	for _, locale := range strings.Split(c.Query("locale"), ",") {
+		if locale = strings.TrimSpace(locale); locale != "" {
+			locales = append(locales, locale)
+		}
+	}
+	if len(locales) == 0 {
+		locales = s.engine.LoadedLocales()
+	}
+
+	results := make([]ReloadResult, 0, len(locales))
+	failed := false
+	for _, locale := range locales {
+		ix, err := s.engine.Reload(c.UserContext(), locale)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "Failed to reload dictionary", "locale", locale, "error", err)
+			results = append(results, ReloadResult{Locale: locale, Error: err.Error()})
+			failed = true
+			continue
+		}
+		slog.InfoContext(c.UserContext(), "Reloaded dictionary", "locale", locale, "version", ix.Version)
+		results = append(results, ReloadResult{Locale: locale, Version: ix.Version, Words: ix.Len()})
+	}
+
+	if failed {
+		c.Status(fiber.StatusMultiStatus)
+	}
+	return c.JSON(results)
+}
+
+// watch reloads dictionaries as their files change until ctx is done.
+func (s *Server) watch(ctx context.Context) {
+	if err := s.engine.Watch(ctx); err != nil {
+		slog.Error("Not watching dictionaries for changes", "dir", s.cfg.DictionaryDir, "error", err)
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jvanrhyn/woordsoek/internal/config"
+)
+
+func TestReload(t *testing.T) {
+	cfg := config.Default()
+	cfg.API.Auth.Keys = []config.APIKey{{ID: "ops", Hash: HashKey("admin-key"), Admin: true}}
+	s := newTestServer(t, cfg)
+	app := s.App()
+
+	before := request(t, app, "GET", searchURL, "").Header().Get(dictionaryVersionHeader)
+	if before == "" {
+		t.Fatalf("GET /search returned no %s header", dictionaryVersionHeader)
+	}
+
+	words := "adore\nroad\nrode\ndoor\nodor\ndare\nrodeo"
+	if err := os.WriteFile(filepath.Join(s.cfg.DictionaryDir, "test.txt"), []byte(words), 0644); err != nil {
+		t.Fatalf("Failed to rewrite test dictionary: %v", err)
+	}
+
+	rec := request(t, app, "POST", "/admin/reload", "admin-key")
+	var results []ReloadResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("POST /admin/reload returned an undecodable body: %v", err)
+	}
+	if rec.Code != 200 || len(results) != 1 || results[0].Locale != "test" || results[0].Words != 7 || results[0].Version == before {
+		t.Fatalf("POST /admin/reload returned %d %+v; expected the test locale with 7 words and a new version", rec.Code, results)
+	}
+
+	rec = request(t, app, "GET", searchURL, "")
+	var response SearchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("GET /search returned an undecodable body: %v", err)
+	}
+	if response.DictionaryVersion != results[0].Version || rec.Header().Get(dictionaryVersionHeader) != results[0].Version || response.Count != 6 {
+		t.Errorf("GET /search after reload returned %+v; expected 6 words from version %s", response, results[0].Version)
+	}
+
+	if rec := request(t, app, "POST", "/admin/reload?locale=test,missing", "admin-key"); rec.Code != 207 {
+		t.Errorf("POST /admin/reload with a missing locale returned %d; expected 207", rec.Code)
+	}
+
+	metrics := request(t, app, "GET", "/metrics", "").Body.String()
+	for _, expected := range []string{
+		`woordsoek_dictionary_info{locale="test",version="` + results[0].Version + `"} 1`,
+		`woordsoek_dictionary_reloads_total{locale="test",result="ok"} 2`,
+		`woordsoek_dictionary_reloads_total{locale="missing",result="failed"} 1`,
+	} {
+		if !strings.Contains(metrics, expected) {
+			t.Errorf("GET /metrics does not contain %s", expected)
+		}
+	}
+	if strings.Contains(metrics, `version="`+before+`"`) {
+		t.Errorf("GET /metrics still reports the replaced version %s", before)
+	}
+}
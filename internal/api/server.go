@@ -1,71 +1,339 @@
 package api
 
 import (
+	"context"
 	"log/slog"
+	"net/netip"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/jvanrhyn/woordsoek/internal/config"
 	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"github.com/jvanrhyn/woordsoek/internal/render"
+	"github.com/jvanrhyn/woordsoek/internal/web"
 	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+const (
+	// dictionaryVersionHeader carries the version of the dictionary that
+	// answered a search.
+	dictionaryVersionHeader = "X-Dictionary-Version"
+
+	// nextCursorHeader carries the cursor of the next page of search
+	// results, for formats that cannot hold it in the body.
+	nextCursorHeader = "X-Next-Cursor"
+)
+
+// SearchResponse is returned by /search. DictionaryVersion identifies the
+// word list that answered; it changes whenever the dictionary is reloaded
+// with different words. Count is the total number of results, of which
+// Results holds one page; Next is the cursor of the following page. Groups
+// holds the same page bucketed by length when group=length is requested.
 type SearchResponse struct {
-	Parameters map[string]string `json:"parameters"`
-	Count      int               `json:"count"`
-	Results    []string          `json:"results"`
+	Parameters        map[string]string `json:"parameters"`
+	DictionaryVersion string            `json:"dictionaryVersion"`
+	Count             int               `json:"count"`
+	Results           []string          `json:"results"`
+	Groups            []woordsoek.Group `json:"groups,omitempty"`
+	Next              string            `json:"next,omitempty"`
 }
 
-func StartAPIServer() {
-	slog.Info("Starting Woordsoek API server")
-	app := fiber.New()
+// groupByLength is the only grouping offered by /search.
+const groupByLength = "length"
 
-	// Define the search endpoint
-	app.Get("/search", func(c *fiber.Ctx) error {
-		// Extract query parameters
-		locale := c.Get("x-locale")       // Get the x-locale header
-		filename := "dictionaries/en.txt" // Default filename
-
-		// Check if the x-locale header is present
-		if locale != "" {
-			filename = "dictionaries/" + locale + ".txt" // Set filename based on header
+// LocalesResponse is returned by /v1/locales. Default is the locale searched
+// when no x-locale header is sent.
+type LocalesResponse struct {
+	Default string                 `json:"default"`
+	Locales []woordsoek.LocaleInfo `json:"locales"`
+}
+
+// Server is the woordsoek HTTP API.
+type Server struct {
+	cfg     *config.Config
+	engine  *woordsoek.Engine
+	app     *fiber.App
+	metrics *httpMetrics
+
+	keys       *keyStore
+	keyLimiter *limiter
+	ipLimiter  *limiter
+	proxies    []netip.Prefix // of api.trusted_proxies
+
+	// h2 serves the HTTP/2 connections when api.http2 is enabled
+	h2 *h2Server
+
+	// draining is set once shutdown starts so that /readyz takes the
+	// instance out of rotation while in-flight requests finish.
+	draining atomic.Bool
+}
+
+// New creates the server with all routes registered. Errors returned by
+// handlers are rendered as RFC 7807 problem details. Request metrics are
+// registered on reg and everything gathered by reg, including the engine
+// metrics, is served on /metrics.
+func New(cfg *config.Config, engine *woordsoek.Engine, reg *prometheus.Registry) (*Server, error) {
+	keys, err := newKeyStore(cfg.API.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		cfg:        cfg,
+		engine:     engine,
+		metrics:    newHTTPMetrics(reg),
+		keys:       keys,
+		keyLimiter: newLimiter(cfg.API.RateLimit.PerKey),
+		ipLimiter:  newLimiter(cfg.API.RateLimit.PerIP),
+	}
+
+	// Strings read from a request outlive it as metric labels, span
+	// attributes and index keys, so Fiber must not reuse their buffers.
+	fiberConfig := fiber.Config{
+		Immutable:    true,
+		ErrorHandler: ErrorHandler,
+		BodyLimit:    cfg.API.BodyLimit,
+		ReadTimeout:  time.Duration(cfg.API.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.API.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.API.IdleTimeout),
+	}
+	if len(cfg.API.TrustedProxies) > 0 {
+		// Only proxies may say who the client is, or anyone could evade
+		// the rate limits by sending their own X-Forwarded-For. The client
+		// is found by clientIP rather than by Fiber, which takes the
+		// leftmost address, the one the client wrote itself.
+		fiberConfig.EnableTrustedProxyCheck = true
+		fiberConfig.TrustedProxies = cfg.API.TrustedProxies
+		fiberConfig.EnableIPValidation = true
+		for _, proxy := range cfg.API.TrustedProxies {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				addr, _ := netip.ParseAddr(proxy)
+				prefix = netip.PrefixFrom(addr, addr.BitLen())
+			}
+			s.proxies = append(s.proxies, prefix.Masked())
 		}
+	}
+	s.app = fiber.New(fiberConfig)
 
-		slog.Info("Searching for words in " + filename)
+	if origins := cfg.API.CORS.AllowOrigins; len(origins) > 0 {
+		s.app.Use(cors.New(cors.Config{
+			AllowOrigins:  strings.Join(origins, ","),
+			AllowHeaders:  "Accept, Authorization, Content-Type, If-None-Match, X-API-Key, X-Locale",
+			ExposeHeaders: "ETag, Retry-After, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, " + dictionaryVersionHeader + ", " + nextCursorHeader,
+			MaxAge:        int(time.Duration(cfg.API.CORS.MaxAge).Seconds()),
+		}))
+	}
 
-		singleLetter := c.Query("singleLetter")
-		sixCharString := c.Query("sixCharString")
-		lengthStr := c.Query("length")
+	s.app.Get("/healthz", s.healthz)
+	s.app.Get("/readyz", s.readyz)
 
-		// Convert length to int
-		length, err := strconv.Atoi(lengthStr)
+	s.app.Use(tracingMiddleware)
+	s.app.Use(s.metrics.middleware)
+	s.app.Get("/metrics", metricsHandler(reg))
+
+	// Define the search endpoint
+	s.app.Get("/search", s.authenticate, s.rateLimit, s.search)
+	s.app.Get("/v1/search/hints", s.authenticate, s.rateLimit, s.hints)
+	s.app.Post("/v1/search/batch", s.authenticate, s.rateLimit, s.searchBatch)
+	s.app.Get("/ws/search", upgradeLive, s.authenticate, s.rateLimit, websocket.New(s.liveSearch))
+	// Listing the dictionaries needs no key so that the web UI can offer
+	// them before asking for one
+	s.app.Get("/v1/locales", s.rateLimit, s.locales)
+
+	admin := s.app.Group("/admin", s.requireAdmin)
+	admin.Get("/keys", s.listKeys)
+	admin.Delete("/keys/:id", s.revokeKey)
+	admin.Post("/reload", s.reload)
+
+	if cfg.API.WebUI {
+		s.app.Use("/", web.Handler())
+	}
+
+	return s, nil
+}
+
+// App returns the underlying Fiber application, e.g. for app.Test.
+func (s *Server) App() *fiber.App {
+	return s.app
+}
+
+func (s *Server) search(c *fiber.Ctx) error {
+	q, err := s.parseQuery(c)
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(c.UserContext(), "Searching for words", "locale", q.Locale)
+
+	limitStr := c.Query("limit")
+	sortStr := c.Query("sort")
+	cursor := c.Query("cursor")
+	group := c.Query("group")
+
+	// An absent limit returns every result
+	limit := 0
+	if limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
 		if err != nil {
-			length = 0 // Default value if conversion fails
+			return errors.Wrap(errors.CodeInvalidQuery, "limit must be a whole number", err)
 		}
+	}
+	order, err := woordsoek.ParseSort(sortStr)
+	if err != nil {
+		return err
+	}
+	if group != "" && group != groupByLength {
+		return errors.New(errors.CodeInvalidQuery, "group must be length")
+	}
+	format, err := s.negotiateFormat(c)
+	if err != nil {
+		return err
+	}
+
+	page := woordsoek.PageRequest{Sort: order, Limit: limit, Cursor: cursor}
+	result, err := s.engine.SearchPage(c.UserContext(), q, page)
+	if err != nil {
+		return err
+	}
+	c.Locals(localeKey, q.Locale)
+
+	c.Set(dictionaryVersionHeader, result.Version)
+	etag := searchETag(result.Version, q, page, format, group)
+	s.setCacheHeaders(c, etag)
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	// Create the response object
+	response := SearchResponse{
+		Parameters: map[string]string{
+			"singleLetter":  q.SingleLetter,
+			"sixCharString": q.SixCharString,
+			"length":        c.Query("length"),
+			"limit":         limitStr,
+			"sort":          string(order),
+			"cursor":        cursor,
+			"group":         group,
+		},
+		DictionaryVersion: result.Version,
+		Count:             result.Total,
+		Results:           result.Words,
+		Next:              result.Next,
+	}
+	if group == groupByLength {
+		response.Groups = woordsoek.GroupByLength(result.Words)
+	}
 
-		// Call the search function from woordsoek package
-		results, err := woordsoek.SearchForMatchingWords(filename, singleLetter, sixCharString, length)
+	slog.InfoContext(c.UserContext(), "Found", "wordcount", result.Total, "cached", result.Cached)
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	if result.Next != "" {
+		// Formats other than JSON have nowhere else to put the cursor
+		c.Set(nextCursorHeader, result.Next)
+	}
+	return render.Render(c.Response().BodyWriter(), format, render.Results{
+		Query:    q,
+		Words:    result.Words,
+		Document: response,
+	})
+}
+
+// parseQuery reads the query shared by /search and /v1/search/hints: the
+// locale from the x-locale header, falling back to the configured one, and
+// the letters and length from the query string. An absent length means any
+// length.
+func (s *Server) parseQuery(c *fiber.Ctx) (woordsoek.Query, error) {
+	q := woordsoek.Query{
+		Locale:        c.Get("x-locale"),
+		SingleLetter:  c.Query("singleLetter"),
+		SixCharString: c.Query("sixCharString"),
+	}
+	if q.Locale == "" {
+		q.Locale = s.cfg.API.DefaultLocale
+	}
+	if lengthStr := c.Query("length"); lengthStr != "" {
+		length, err := strconv.Atoi(lengthStr)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(errors.CustomError{Message: err.Error()})
+			return woordsoek.Query{}, errors.Wrap(errors.CodeInvalidQuery, "length must be a whole number", err)
 		}
+		q.Length = length
+	}
+	return q, nil
+}
 
-		// Create the response object
-		response := SearchResponse{
-			Parameters: map[string]string{
-				"singleLetter":  singleLetter,
-				"sixCharString": sixCharString,
-				"length":        lengthStr,
-			},
-			Count:   len(results),
-			Results: results,
-		}
+// locales lists the available dictionaries with their names.
+func (s *Server) locales(c *fiber.Ctx) error {
+	infos, err := s.engine.LocaleInfos()
+	if err != nil {
+		return err
+	}
+	return c.JSON(LocalesResponse{Default: s.cfg.API.DefaultLocale, Locales: infos})
+}
 
-		slog.Info("Found", "wordcount", len(results))
-		return c.JSON(response)
-	})
+// negotiateFormat picks the response format from the format parameter or,
+// when it is absent, from the Accept header. JSON is used when neither
+// expresses a preference.
+func (s *Server) negotiateFormat(c *fiber.Ctx) (render.Format, error) {
+	if f := c.Query("format"); f != "" {
+		return render.ParseFormat(f)
+	}
+	mediaType := c.Accepts(render.MediaTypes()...)
+	format, ok := render.ForMediaType(mediaType)
+	if !ok {
+		return "", fiber.NewError(fiber.StatusNotAcceptable, "supported types are "+strings.Join(render.MediaTypes(), ", "))
+	}
+	return format, nil
+}
 
-	// Start the server
-	err := app.Listen(":3000")
+// Run preloads the configured locales in the background and serves requests
+// until ctx is cancelled. It then stops accepting connections and waits up to
+// api.shutdown_timeout for in-flight requests before returning.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := s.listen()
 	if err != nil {
-		return
+		return err
+	}
+
+	go s.preload(ctx)
+	if s.cfg.API.Watch {
+		go s.watch(ctx)
+	}
+
+	listenErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting Woordsoek API server", "addr", ln.Addr().String(),
+			"tls", s.cfg.API.TLS.Enabled(), "http2", s.cfg.API.HTTP2)
+		listenErr <- s.app.Listener(ln)
+	}()
+
+	select {
+	case err := <-listenErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down Woordsoek API server, draining requests")
+	s.draining.Store(true)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(s.cfg.API.ShutdownTimeout))
+	defer cancel()
+	if err := s.app.ShutdownWithContext(shutdownCtx); err != nil {
+		return err
+	}
+	if s.h2 != nil {
+		if err := s.h2.shutdown(shutdownCtx); err != nil {
+			return err
+		}
+	}
+	if err := <-listenErr; err != nil {
+		return err
 	}
+	slog.Info("Woordsoek API server stopped")
+	return nil
 }
@@ -0,0 +1,446 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newTestApp creates an app over a temporary "test" dictionary, which is
+// also the default locale.
+func newTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+	return newTestServer(t, config.Default()).App()
+}
+
+// newTestServer creates a server from cfg over a temporary "test" dictionary,
+// which is also the default locale.
+func newTestServer(t *testing.T, cfg *config.Config) *Server {
+	t.Helper()
+	woordsoek.LoadVowelForms()
+
+	dir := t.TempDir()
+	words := []string{"adore", "road", "rode", "door", "odor", "dare"}
+	if err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte(strings.Join(words, "\n")), 0644); err != nil {
+		t.Fatalf("Failed to write test dictionary: %v", err)
+	}
+
+	cfg.DictionaryDir = dir
+	cfg.API.DefaultLocale = "test"
+
+	reg := prometheus.NewRegistry()
+	engine := woordsoek.NewEngine(dir,
+		woordsoek.WithMetrics(woordsoek.NewMetrics(reg)),
+		woordsoek.WithResultCache(cfg.Cache.Size),
+	)
+	s, err := New(cfg, engine, reg)
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	return s
+}
+
+func TestSearch(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/search?singleLetter=o&sixCharString=aedr", nil))
+	if err != nil {
+		t.Fatalf("app.Test returned an error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /search returned status %d; expected 200", resp.StatusCode)
+	}
+
+	var response SearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("GET /search returned an undecodable body: %v", err)
+	}
+	expected := []string{"adore", "door", "odor", "road", "rode"}
+	if response.Count != len(expected) || !reflect.DeepEqual(response.Results, expected) {
+		t.Errorf("GET /search returned %+v; expected results %v", response, expected)
+	}
+}
+
+func TestSearchProblemResponses(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		url      string
+		locale   string
+		accept   string
+		status   int
+		expected errors.Code
+	}{
+		{"/search?singleLetter=o&sixCharString=aedor", "does-not-exist", "", 404, errors.CodeDictionaryNotFound},
+		{"/search?singleLetter=o&sixCharString=aedor", "../test", "", 400, errors.CodeInvalidQuery},
+		{"/search?singleLetter=o&sixCharString=aedor&length=abc", "", "", 400, errors.CodeInvalidQuery},
+		{"/does-not-exist", "", "", 404, errors.CodeNotFound},
+		{"/search?singleLetter=o&sixCharString=aedor", "", "image/png", 406, errors.CodeNotAcceptable},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.url, nil)
+		if test.locale != "" {
+			req.Header.Set("x-locale", test.locale)
+		}
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test(%s) returned an error: %v", test.url, err)
+		}
+
+		if resp.StatusCode != test.status {
+			t.Errorf("GET %s returned status %d; expected %d", test.url, resp.StatusCode, test.status)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != problemContentType {
+			t.Errorf("GET %s returned content type %q; expected %q", test.url, ct, problemContentType)
+		}
+
+		var problem Problem
+		if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+			t.Fatalf("GET %s returned an undecodable body: %v", test.url, err)
+		}
+		if problem.Code != test.expected || problem.Status != test.status || problem.Detail == "" {
+			t.Errorf("GET %s returned problem %+v; expected code %q", test.url, problem, test.expected)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	app := newTestApp(t)
+
+	for _, url := range []string{"/search?singleLetter=o&sixCharString=aedr", "/search?singleLetter=o&sixCharString=aedr&length=x"} {
+		if _, err := app.Test(httptest.NewRequest("GET", url, nil)); err != nil {
+			t.Fatalf("app.Test(%s) returned an error: %v", url, err)
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil))
+	if err != nil {
+		t.Fatalf("app.Test returned an error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+
+	for _, expected := range []string{
+		`woordsoek_http_requests_total{locale="test",method="GET",route="/search",status="200"} 1`,
+		`woordsoek_http_requests_total{locale="none",method="GET",route="/search",status="400"} 1`,
+		`woordsoek_http_request_duration_seconds_count{locale="test",method="GET",route="/search"} 1`,
+		`woordsoek_search_results_count{locale="test"} 1`,
+		`woordsoek_dictionary_load_duration_seconds_count{locale="test"} 1`,
+		`woordsoek_index_words{locale="test"} 6`,
+		`woordsoek_cache_lookups_total{cache="index",result="miss"} 1`,
+		`woordsoek_cache_lookups_total{cache="result",result="miss"} 1`,
+	} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("GET /metrics does not contain %s", expected)
+		}
+	}
+}
+
+func TestMetricsAcrossLocales(t *testing.T) {
+	cfg := config.Default()
+	s := newTestServer(t, cfg)
+	app := s.App()
+	for _, locale := range []string{"aa", "bbbb"} {
+		if err := os.WriteFile(filepath.Join(cfg.DictionaryDir, locale+".txt"), []byte("adore\nroad"), 0644); err != nil {
+			t.Fatalf("Failed to write dictionary %s: %v", locale, err)
+		}
+	}
+
+	for _, test := range []struct{ method, locale string }{
+		{"GET", "aa"},
+		{"POST", "bbbb"},
+		{"GET", "test"},
+		{"DELETE", "aa"},
+	} {
+		req := httptest.NewRequest(test.method, "/search?singleLetter=o&sixCharString=aedr", nil)
+		req.Header.Set("x-locale", test.locale)
+		if _, err := app.Test(req); err != nil {
+			t.Fatalf("app.Test(%s %s) returned an error: %v", test.method, test.locale, err)
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil))
+	if err != nil {
+		t.Fatalf("app.Test returned an error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("GET /metrics returned status %d: %s", resp.StatusCode, body)
+	}
+	if locales := s.engine.LoadedLocales(); !reflect.DeepEqual(locales, []string{"aa", "test"}) {
+		t.Errorf("LoadedLocales() = %v; expected [aa test]", locales)
+	}
+}
+
+func TestSearchCaching(t *testing.T) {
+	s := newTestServer(t, config.Default())
+	app := s.App()
+
+	get := func(url, ifNoneMatch string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("GET", url, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test(%s) returned an error: %v", url, err)
+		}
+		return resp
+	}
+
+	resp := get("/search?singleLetter=o&sixCharString=aedr", "")
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != 200 || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("GET /search returned %d with ETag %q; expected 200 with a weak ETag", resp.StatusCode, etag)
+	}
+	if cc := resp.Header.Get("Cache-Control"); cc != "public, max-age=300" {
+		t.Errorf("GET /search returned Cache-Control %q; expected public, max-age=300", cc)
+	}
+	if vary := resp.Header.Get("Vary"); vary != "X-Locale, Accept" {
+		t.Errorf("GET /search returned Vary %q; expected X-Locale, Accept", vary)
+	}
+
+	tests := []struct {
+		url         string
+		ifNoneMatch string
+		status      int
+	}{
+		{"/search?singleLetter=o&sixCharString=aedr", etag, 304},
+		{"/search?singleLetter=o&sixCharString=rdea", `"other", ` + etag, 304}, // same normalized query
+		{"/search?singleLetter=o&sixCharString=aedr", "*", 304},
+		{"/search?singleLetter=o&sixCharString=aed", etag, 200},
+		{"/search?singleLetter=o&sixCharString=aedr", `W/"other"`, 200},
+	}
+	for _, test := range tests {
+		if resp := get(test.url, test.ifNoneMatch); resp.StatusCode != test.status {
+			t.Errorf("GET %s with If-None-Match %s returned %d; expected %d", test.url, test.ifNoneMatch, resp.StatusCode, test.status)
+		}
+	}
+
+	// Changing the dictionary changes the ETag once it is reloaded
+	words := "adore\nroad\nrode\ndoor\nodor\ndare\nrodeo"
+	if err := os.WriteFile(filepath.Join(s.cfg.DictionaryDir, "test.txt"), []byte(words), 0644); err != nil {
+		t.Fatalf("Failed to rewrite test dictionary: %v", err)
+	}
+	s.engine.Invalidate("test")
+	resp = get("/search?singleLetter=o&sixCharString=aedr", etag)
+	if resp.StatusCode != 200 || resp.Header.Get("ETag") == etag {
+		t.Errorf("GET /search after a reload returned %d with ETag %q; expected 200 with a new ETag", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+}
+
+func TestSearchPaging(t *testing.T) {
+	app := newTestApp(t)
+
+	var got []string
+	url := "/search?singleLetter=o&sixCharString=aedr&sort=length_desc&limit=2"
+	for pages := 0; url != ""; pages++ {
+		if pages > 3 {
+			t.Fatal("GET /search keeps returning pages")
+		}
+		resp, err := app.Test(httptest.NewRequest("GET", url, nil))
+		if err != nil {
+			t.Fatalf("app.Test(%s) returned an error: %v", url, err)
+		}
+		var response SearchResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("GET %s returned an undecodable body: %v", url, err)
+		}
+		if resp.StatusCode != 200 || response.Count != 5 || len(response.Results) > 2 {
+			t.Fatalf("GET %s returned %d %+v; expected at most 2 of 5 results", url, resp.StatusCode, response)
+		}
+		got = append(got, response.Results...)
+
+		url = ""
+		if response.Next != "" {
+			url = "/search?singleLetter=o&sixCharString=aedr&sort=length_desc&limit=2&cursor=" + response.Next
+		}
+	}
+	if expected := []string{"adore", "door", "odor", "road", "rode"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("GET /search pages = %v; expected %v", got, expected)
+	}
+
+	for _, url := range []string{
+		"/search?singleLetter=o&sixCharString=aedr&sort=random",
+		"/search?singleLetter=o&sixCharString=aedr&limit=many",
+		"/search?singleLetter=o&sixCharString=aedr&cursor=bogus",
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", url, nil))
+		if err != nil {
+			t.Fatalf("app.Test(%s) returned an error: %v", url, err)
+		}
+		if resp.StatusCode != 400 {
+			t.Errorf("GET %s returned status %d; expected 400", url, resp.StatusCode)
+		}
+	}
+}
+
+func TestSearchFormats(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		url         string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/search?singleLetter=o&sixCharString=aedr", "text/plain", 200, "text/plain; charset=utf-8", "adore\ndoor\nodor\nroad\nrode\n"},
+		{"/search?singleLetter=o&sixCharString=aedr&limit=2", "text/csv;q=0.9, application/json;q=0.1", 200, "text/csv; charset=utf-8", "word,length,score,pangram\nadore,5,12,true\ndoor,4,1,false\n"},
+		{"/search?singleLetter=o&sixCharString=aedr&limit=1&format=ndjson", "text/plain", 200, "application/x-ndjson", `{"word":"adore","length":5,"score":12,"pangram":true}` + "\n"},
+		{"/search?singleLetter=o&sixCharString=aedr", "image/png", 406, problemContentType, ""},
+		{"/search?singleLetter=o&sixCharString=aedr&format=xml", "", 400, problemContentType, ""},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.url, nil)
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test(%s) returned an error: %v", test.url, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != test.status || resp.Header.Get("Content-Type") != test.contentType {
+			t.Errorf("GET %s (Accept %q) returned %d %q; expected %d %q",
+				test.url, test.accept, resp.StatusCode, resp.Header.Get("Content-Type"), test.status, test.contentType)
+		}
+		if test.body != "" && string(body) != test.body {
+			t.Errorf("GET %s (Accept %q) returned %q; expected %q", test.url, test.accept, body, test.body)
+		}
+	}
+}
+
+func TestLocales(t *testing.T) {
+	cfg := config.Default()
+	cfg.API.Auth.Required = true
+	app := newTestServer(t, cfg).App()
+
+	// Listing the dictionaries needs no key, unlike searching them
+	rec := request(t, app, "GET", "/v1/locales", "")
+	if rec.Code != 200 {
+		t.Fatalf("GET /v1/locales returned status %d; expected 200", rec.Code)
+	}
+	var response LocalesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("GET /v1/locales returned an undecodable body: %v", err)
+	}
+	expected := LocalesResponse{
+		Default: "test",
+		Locales: []woordsoek.LocaleInfo{{Locale: "test", Name: "test"}},
+	}
+	if !reflect.DeepEqual(response, expected) {
+		t.Errorf("GET /v1/locales returned %+v; expected %+v", response, expected)
+	}
+}
+
+func TestWebUI(t *testing.T) {
+	tests := []struct {
+		enabled     bool
+		url         string
+		status      int
+		contentType string
+	}{
+		{true, "/", 200, "text/html"},
+		{true, "/app.js", 200, "text/javascript"},
+		{true, "/style.css", 200, "text/css"},
+		{true, "/missing.js", 404, ""},
+		{false, "/", 404, ""},
+	}
+	for _, test := range tests {
+		cfg := config.Default()
+		cfg.API.WebUI = test.enabled
+		rec := request(t, newTestServer(t, cfg).App(), "GET", test.url, "")
+		if rec.Code != test.status {
+			t.Errorf("GET %s with web UI %v returned status %d; expected %d", test.url, test.enabled, rec.Code, test.status)
+			continue
+		}
+		if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, test.contentType) {
+			t.Errorf("GET %s returned Content-Type %q; expected %q", test.url, contentType, test.contentType)
+		}
+	}
+}
+
+func TestCORS(t *testing.T) {
+	cfg := config.Default()
+	cfg.API.CORS.AllowOrigins = []string{"https://example.com"}
+	app := newTestServer(t, cfg).App()
+
+	tests := []struct {
+		method  string
+		origin  string
+		allowed bool
+	}{
+		{"OPTIONS", "https://example.com", true},
+		{"GET", "https://example.com", true},
+		{"OPTIONS", "https://evil.example", false},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, "/search?singleLetter=o&sixCharString=aedr", nil)
+		req.Header.Set("Origin", test.origin)
+		req.Header.Set("Access-Control-Request-Method", "GET")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test returned an error: %v", err)
+		}
+		if allowed := resp.Header.Get("Access-Control-Allow-Origin") == test.origin; allowed != test.allowed {
+			t.Errorf("%s from %s allowed = %v; expected %v", test.method, test.origin, allowed, test.allowed)
+		}
+		if test.allowed && test.method == "GET" && !strings.Contains(resp.Header.Get("Access-Control-Expose-Headers"), dictionaryVersionHeader) {
+			t.Errorf("GET from %s exposes %q; expected it to include %s", test.origin, resp.Header.Get("Access-Control-Expose-Headers"), dictionaryVersionHeader)
+		}
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	tests := []struct {
+		trusted   []string
+		forwarded []string // by each request
+		expected  int
+	}{
+		// Each forwarded client gets its own limit behind a trusted proxy
+		{[]string{"0.0.0.0/8"}, []string{"192.0.2.1", "192.0.2.2"}, 200},
+		// Otherwise every request counts against the proxy's own IP
+		{nil, []string{"192.0.2.1", "192.0.2.2"}, 429},
+		// A client cannot get a new limit by making up the hops before it
+		{[]string{"0.0.0.0/8"}, []string{"198.51.100.1, 192.0.2.1", "198.51.100.2, 192.0.2.1"}, 429},
+		// Nor by claiming to be behind another trusted proxy
+		{[]string{"0.0.0.0/8", "10.0.0.0/8"}, []string{"198.51.100.1, 192.0.2.1, 10.0.0.1", "198.51.100.2, 192.0.2.1, 10.0.0.2"}, 429},
+	}
+	for _, test := range tests {
+		cfg := config.Default()
+		cfg.API.TrustedProxies = test.trusted
+		cfg.API.RateLimit.PerIP = config.RateLimit{RequestsPerMinute: 1, Burst: 1}
+		app := newTestServer(t, cfg).App()
+
+		var status int
+		for _, forwarded := range test.forwarded {
+			req := httptest.NewRequest("GET", "/search?singleLetter=o", nil)
+			req.Header.Set("X-Forwarded-For", forwarded)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test returned an error: %v", err)
+			}
+			status = resp.StatusCode
+		}
+		if status != test.expected {
+			t.Errorf("Second request forwarded as %q behind proxies %v got status %d; expected %d", test.forwarded[1], test.trusted, status, test.expected)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	recorderOnce sync.Once
+	recorder     *tracetest.SpanRecorder
+)
+
+// spanRecorder returns the recorder of the global tracer provider. The
+// global provider only delegates to the first one set, so every test shares
+// it and looks at the spans that ended after it started.
+func spanRecorder() *tracetest.SpanRecorder {
+	recorderOnce.Do(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+	return recorder
+}
+
+func TestTracingSpans(t *testing.T) {
+	recorder := spanRecorder()
+	ended := len(recorder.Ended())
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previousPropagator)
+
+	app := newTestApp(t)
+	req := httptest.NewRequest("GET", "/search?singleLetter=o&sixCharString=aedr", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if _, err := app.Test(req); err != nil {
+		t.Fatalf("app.Test returned an error: %v", err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended()[ended:] {
+		spans[span.Name()] = span
+	}
+
+	for _, name := range []string{"GET /search", "Engine.SearchPage", "LoadIndex", "Index.lookup", "fold", "sort"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("no %q span was recorded", name)
+			continue
+		}
+		if traceID := span.SpanContext().TraceID().String(); traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("span %q has trace ID %s; expected the propagated trace", name, traceID)
+		}
+	}
+}
+
+func TestTracingAttributesOutliveRequest(t *testing.T) {
+	recorder := spanRecorder()
+	ended := len(recorder.Ended())
+
+	app := newTestApp(t)
+	for _, test := range []struct{ url, locale string }{
+		{"/search?singleLetter=o&sixCharString=aedr", "test"},
+		{"/v1/locales", ""},
+		{"/search?singleLetter=x&sixCharString=zzzz", "zz"},
+	} {
+		req := httptest.NewRequest("GET", test.url, nil)
+		if test.locale != "" {
+			req.Header.Set("x-locale", test.locale)
+		}
+		if _, err := app.Test(req); err != nil {
+			t.Fatalf("app.Test(%s) returned an error: %v", test.url, err)
+		}
+	}
+
+	locales := make(map[string]bool)
+	for _, span := range recorder.Ended()[ended:] {
+		attrs := make(map[string]string)
+		for _, kv := range span.Attributes() {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+		}
+		switch span.Name() {
+		case "GET /search":
+			if attrs["url.path"] != "/search" || attrs["http.request.method"] != "GET" {
+				t.Errorf("span %q has attributes %v", span.Name(), attrs)
+			}
+		case "Engine.SearchPage":
+			locales[attrs["dictionary.locale"]] = true
+		}
+	}
+	if !locales["test"] || !locales["zz"] {
+		t.Errorf("Engine.SearchPage spans have locales %v; expected test and zz", locales)
+	}
+}
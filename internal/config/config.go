@@ -0,0 +1,560 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the configuration file read when no other file is given.
+// It is optional; a missing default file is not an error.
+const DefaultFile = "woordsoek.yaml"
+
+// Config holds the settings shared by all woordsoek binaries.
+//
+// Values are resolved in order of increasing precedence: built-in defaults,
+// the configuration file (YAML or TOML), environment variables (including
+// those loaded from .env) and finally command-line flags.
+type Config struct {
+	Locale        string         `yaml:"locale" toml:"locale"`
+	DictionaryDir string         `yaml:"dictionary_dir" toml:"dictionary_dir"`
+	API           APIConfig      `yaml:"api" toml:"api"`
+	Cache         CacheConfig    `yaml:"cache" toml:"cache"`
+	Database      DatabaseConfig `yaml:"database" toml:"database"`
+	Log           LogConfig      `yaml:"log" toml:"log"`
+	Tracing       TracingConfig  `yaml:"tracing" toml:"tracing"`
+	TUI           TUIConfig      `yaml:"tui" toml:"tui"`
+
+	// set holds the keys given by the file, the environment or a flag.
+	set map[string]bool
+}
+
+// APIConfig configures the HTTP API server. The locales in Preload are
+// loaded at startup and /readyz only reports ready once they are in memory;
+// "*" preloads every dictionary. On shutdown in-flight requests are given
+// ShutdownTimeout to complete. Search responses may be cached by clients and
+// proxies for CacheMaxAge. With Watch, loaded dictionaries are reloaded when
+// their file changes. Live searches over WebSocket wait for LiveDebounce of
+// quiet before searching. WebUI serves the browser interface on /.
+//
+// Requests are limited to BodyLimit bytes. ReadTimeout and WriteTimeout bound
+// reading a request and writing its response, and idle keep-alive
+// connections are closed after IdleTimeout; 0 disables a timeout. The client
+// IP used for rate limiting is taken from X-Forwarded-For only when the
+// connection comes from one of TrustedProxies, given as IPs or CIDR ranges.
+type APIConfig struct {
+	Addr            string          `yaml:"addr" toml:"addr"`
+	DefaultLocale   string          `yaml:"default_locale" toml:"default_locale"`
+	Preload         []string        `yaml:"preload" toml:"preload"`
+	ShutdownTimeout Duration        `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	CacheMaxAge     Duration        `yaml:"cache_max_age" toml:"cache_max_age"`
+	Watch           bool            `yaml:"watch" toml:"watch"`
+	LiveDebounce    Duration        `yaml:"live_debounce" toml:"live_debounce"`
+	WebUI           bool            `yaml:"web_ui" toml:"web_ui"`
+	BodyLimit       int             `yaml:"body_limit" toml:"body_limit"`
+	ReadTimeout     Duration        `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout    Duration        `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout     Duration        `yaml:"idle_timeout" toml:"idle_timeout"`
+	TrustedProxies  []string        `yaml:"trusted_proxies" toml:"trusted_proxies"`
+	HTTP2           bool            `yaml:"http2" toml:"http2"`
+	TLS             TLSConfig       `yaml:"tls" toml:"tls"`
+	CORS            CORSConfig      `yaml:"cors" toml:"cors"`
+	Batch           BatchConfig     `yaml:"batch" toml:"batch"`
+	Auth            AuthConfig      `yaml:"auth" toml:"auth"`
+	RateLimit       RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+}
+
+// TLSConfig enables HTTPS with the certificate and key in CertFile and
+// KeyFile. For local development SelfSigned generates a throwaway
+// certificate for localhost instead.
+type TLSConfig struct {
+	CertFile   string `yaml:"cert_file" toml:"cert_file"`
+	KeyFile    string `yaml:"key_file" toml:"key_file"`
+	SelfSigned bool   `yaml:"self_signed" toml:"self_signed"`
+}
+
+// Enabled reports whether the API server should serve HTTPS.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || t.SelfSigned
+}
+
+// CORSConfig lets browser pages from AllowOrigins, such as
+// "https://example.com", "https://*.example.com" or "*" for any, call the
+// API. Browsers may cache preflight responses for MaxAge. CORS is disabled
+// when AllowOrigins is empty.
+type CORSConfig struct {
+	AllowOrigins []string `yaml:"allow_origins" toml:"allow_origins"`
+	MaxAge       Duration `yaml:"max_age" toml:"max_age"`
+}
+
+// BatchConfig limits batch searches: at most MaxQueries per request, run
+// concurrently on Workers goroutines.
+type BatchConfig struct {
+	MaxQueries int `yaml:"max_queries" toml:"max_queries"`
+	Workers    int `yaml:"workers" toml:"workers"`
+}
+
+// AuthConfig lists the API keys accepted by the API server. Keys are stored
+// as the hex SHA-256 of the key, never in plain text, either here or in
+// KeysFile (a YAML list of the same entries). When Required is false,
+// requests without a key are still served but are only rate limited by IP.
+type AuthConfig struct {
+	Required bool     `yaml:"required" toml:"required"`
+	KeysFile string   `yaml:"keys_file" toml:"keys_file"`
+	Keys     []APIKey `yaml:"keys" toml:"keys"`
+}
+
+// APIKey is a hashed API key. Admin keys may use the /admin endpoints.
+type APIKey struct {
+	ID      string `yaml:"id" toml:"id" json:"id"`
+	Hash    string `yaml:"hash" toml:"hash" json:"-"`
+	Admin   bool   `yaml:"admin,omitempty" toml:"admin" json:"admin"`
+	Revoked bool   `yaml:"revoked,omitempty" toml:"revoked" json:"revoked"`
+}
+
+// RateLimitConfig sets token-bucket limits for requests carrying an API key
+// and for each client IP. A limit of 0 requests per minute disables it.
+type RateLimitConfig struct {
+	PerKey RateLimit `yaml:"per_key" toml:"per_key"`
+	PerIP  RateLimit `yaml:"per_ip" toml:"per_ip"`
+}
+
+// RateLimit allows RequestsPerMinute on average, with bursts of up to Burst
+// requests.
+type RateLimit struct {
+	RequestsPerMinute int `yaml:"requests_per_minute" toml:"requests_per_minute"`
+	Burst             int `yaml:"burst" toml:"burst"`
+}
+
+// CacheConfig sizes the in-memory search result cache shared by the API and
+// the TUI. Size is the number of distinct queries kept; 0 disables it.
+type CacheConfig struct {
+	Size int `yaml:"size" toml:"size"`
+}
+
+type DatabaseConfig struct {
+	DSN string `yaml:"dsn" toml:"dsn"`
+}
+
+// LogConfig controls where and how much is logged. Log files are rotated once
+// they reach MaxSizeMB and pruned beyond MaxBackups files or MaxAgeDays days.
+// TraceSample logs one in every TraceSample words scanned at debug level.
+type LogConfig struct {
+	Level       string `yaml:"level" toml:"level"`
+	Format      string `yaml:"format" toml:"format"`
+	Output      string `yaml:"output" toml:"output"`
+	Dir         string `yaml:"dir" toml:"dir"`
+	MaxSizeMB   int    `yaml:"max_size_mb" toml:"max_size_mb"`
+	MaxBackups  int    `yaml:"max_backups" toml:"max_backups"`
+	MaxAgeDays  int    `yaml:"max_age_days" toml:"max_age_days"`
+	TraceSample int    `yaml:"trace_sample" toml:"trace_sample"`
+}
+
+// TracingConfig selects where OpenTelemetry spans are exported. Exporter is
+// none, stdout or otlp; Endpoint and Insecure only apply to otlp. SampleRatio
+// is the fraction of new traces that are recorded.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint"`
+	Insecure    bool    `yaml:"insecure" toml:"insecure"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+// TUIConfig styles the terminal interface. Theme is light, dark or
+// high-contrast. Keys rebinds actions by name, such as export: [ctrl+e];
+// actions left out keep their default keys.
+type TUIConfig struct {
+	Theme string              `yaml:"theme" toml:"theme"`
+	Keys  map[string][]string `yaml:"keys" toml:"keys"`
+}
+
+// Default returns the built-in configuration.
+func Default() *Config {
+	return &Config{
+		Locale:        "af-za",
+		DictionaryDir: "dictionaries",
+		API: APIConfig{
+			Addr:            ":3000",
+			DefaultLocale:   "en",
+			ShutdownTimeout: Duration(10 * time.Second),
+			CacheMaxAge:     Duration(5 * time.Minute),
+			Watch:           true,
+			LiveDebounce:    Duration(150 * time.Millisecond),
+			WebUI:           true,
+			BodyLimit:       1 << 20,
+			ReadTimeout:     Duration(10 * time.Second),
+			WriteTimeout:    Duration(30 * time.Second),
+			IdleTimeout:     Duration(2 * time.Minute),
+			CORS:            CORSConfig{MaxAge: Duration(10 * time.Minute)},
+			Batch:           BatchConfig{MaxQueries: 100, Workers: 8},
+			RateLimit: RateLimitConfig{
+				PerKey: RateLimit{RequestsPerMinute: 600, Burst: 60},
+				PerIP:  RateLimit{RequestsPerMinute: 60, Burst: 20},
+			},
+		},
+		Cache: CacheConfig{
+			Size: 1024,
+		},
+		Database: DatabaseConfig{
+			DSN: "dbname=woordsoek sslmode=disable",
+		},
+		Log: LogConfig{
+			Level:       "info",
+			Format:      "json",
+			Output:      "file",
+			Dir:         "logs",
+			MaxSizeMB:   10,
+			MaxBackups:  7,
+			MaxAgeDays:  30,
+			TraceSample: 1000,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "localhost:4317",
+			SampleRatio: 1,
+		},
+		TUI: TUIConfig{
+			Theme: "dark",
+		},
+	}
+}
+
+// Load resolves the configuration for the binary called name from args
+// (without the program name). The arguments left after flag parsing are
+// returned so callers can handle sub-commands and positional arguments.
+// Binaries with flags of their own define them with extra, so that they can
+// be mixed with the configuration flags.
+func Load(name string, args []string, extra ...func(fs *flag.FlagSet)) (*Config, []string, error) {
+	// A missing .env file is fine, variables may come from the environment
+	_ = godotenv.Load()
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a YAML or TOML configuration file (env WOORDSOEK_CONFIG)")
+
+	// Flag values are collected first and applied last so that they take
+	// precedence over the file and the environment.
+	var flagValues []func(*Config) error
+	for _, s := range settings {
+		s := s
+		collect := func(v string) error {
+			flagValues = append(flagValues, func(c *Config) error {
+				c.markSet(s.key)
+				return s.set(c, v)
+			})
+			return nil
+		}
+		if s.isBool {
+			fs.BoolFunc(s.flag, s.usage+" (env "+s.env+")", collect)
+		} else {
+			fs.Func(s.flag, s.usage+" (env "+s.env+")", collect)
+		}
+	}
+	for _, define := range extra {
+		define(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	cfg := Default()
+
+	path, explicit := *configFile, *configFile != ""
+	if !explicit {
+		path, explicit = os.Getenv("WOORDSOEK_CONFIG"), os.Getenv("WOORDSOEK_CONFIG") != ""
+	}
+	if !explicit {
+		path = DefaultFile
+	}
+	if err := cfg.loadFile(path, explicit); err != nil {
+		return nil, nil, err
+	}
+
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(cfg, v); err != nil {
+				return nil, nil, fmt.Errorf("environment variable %s: %w", s.env, err)
+			}
+			cfg.markSet(s.key)
+		}
+	}
+
+	for _, apply := range flagValues {
+		if err := apply(cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// loadFile merges the YAML or TOML file at path into c. A missing file is only
+// an error when it was asked for explicitly.
+func (c *Config) loadFile(path string, explicit bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil
+		}
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && err != io.EOF {
+			return fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+		var keys map[string]any
+		_ = yaml.Unmarshal(data, &keys)
+		c.markKeys(keys, "")
+	case ".toml":
+		md, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("parsing config file %s: unknown key %s", path, undecoded[0])
+		}
+		for _, key := range md.Keys() {
+			c.markSet(strings.Join(key, "."))
+		}
+	default:
+		return fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	return nil
+}
+
+// IsSet reports whether key, as written in a configuration file such as
+// tui.theme, was given by the file, the environment or a flag rather than
+// left at its default.
+func (c *Config) IsSet(key string) bool {
+	return c.set[key]
+}
+
+func (c *Config) markSet(key string) {
+	if c.set == nil {
+		c.set = make(map[string]bool)
+	}
+	c.set[key] = true
+}
+
+// markKeys marks every key in the YAML mapping m, nested under prefix.
+func (c *Config) markKeys(m map[string]any, prefix string) {
+	for name, v := range m {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		c.markSet(key)
+		if nested, ok := v.(map[string]any); ok {
+			c.markKeys(nested, key)
+		}
+	}
+}
+
+// Validate checks that the configuration is usable.
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Locale == "" {
+		problems = append(problems, "locale must not be empty")
+	}
+	if info, err := os.Stat(c.DictionaryDir); err != nil || !info.IsDir() {
+		problems = append(problems, fmt.Sprintf("dictionary_dir %q is not a directory", c.DictionaryDir))
+	}
+	if _, _, err := net.SplitHostPort(c.API.Addr); err != nil {
+		problems = append(problems, fmt.Sprintf("api.addr %q is not a valid listen address", c.API.Addr))
+	}
+	if c.API.DefaultLocale == "" {
+		problems = append(problems, "api.default_locale must not be empty")
+	}
+	if c.API.ShutdownTimeout <= 0 {
+		problems = append(problems, "api.shutdown_timeout must be positive")
+	}
+	if c.API.CacheMaxAge < 0 {
+		problems = append(problems, "api.cache_max_age cannot be negative")
+	}
+	if c.API.LiveDebounce < 0 {
+		problems = append(problems, "api.live_debounce cannot be negative")
+	}
+	if c.API.BodyLimit < 1 {
+		problems = append(problems, "api.body_limit must be positive")
+	}
+	if c.API.ReadTimeout < 0 || c.API.WriteTimeout < 0 || c.API.IdleTimeout < 0 {
+		problems = append(problems, "api.read_timeout, api.write_timeout and api.idle_timeout cannot be negative")
+	}
+	for _, proxy := range c.API.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				problems = append(problems, fmt.Sprintf("api.trusted_proxies: %q is not an IP address or CIDR range", proxy))
+			}
+		}
+	}
+	if (c.API.TLS.CertFile == "") != (c.API.TLS.KeyFile == "") {
+		problems = append(problems, "api.tls.cert_file and api.tls.key_file must be set together")
+	}
+	if c.API.TLS.SelfSigned && c.API.TLS.CertFile != "" {
+		problems = append(problems, "api.tls.self_signed cannot be combined with api.tls.cert_file")
+	}
+	for _, origin := range c.API.CORS.AllowOrigins {
+		if !validOrigin(origin) || (origin == "*" && len(c.API.CORS.AllowOrigins) > 1) {
+			problems = append(problems, fmt.Sprintf("api.cors.allow_origins: %q must be * on its own or a scheme and host such as https://example.com", origin))
+		}
+	}
+	if c.API.CORS.MaxAge < 0 {
+		problems = append(problems, "api.cors.max_age cannot be negative")
+	}
+	if c.API.Batch.MaxQueries < 1 || c.API.Batch.Workers < 1 {
+		problems = append(problems, "api.batch.max_queries and api.batch.workers must be at least 1")
+	}
+	for _, key := range c.API.Auth.Keys {
+		if key.ID == "" || len(key.Hash) != 64 {
+			problems = append(problems, "api.auth.keys need an id and a 64 character SHA-256 hash")
+			break
+		}
+	}
+	for _, limit := range []struct {
+		name string
+		RateLimit
+	}{{"per_key", c.API.RateLimit.PerKey}, {"per_ip", c.API.RateLimit.PerIP}} {
+		if limit.RequestsPerMinute < 0 || limit.Burst < 0 || (limit.RequestsPerMinute > 0 && limit.Burst == 0) {
+			problems = append(problems, "api.rate_limit."+limit.name+" needs a positive burst when enabled")
+		}
+	}
+	if c.Cache.Size < 0 {
+		problems = append(problems, "cache.size cannot be negative")
+	}
+	if c.Database.DSN == "" {
+		problems = append(problems, "database.dsn must not be empty")
+	}
+	if _, err := parseLevel(c.Log.Level); err != nil {
+		problems = append(problems, "log.level: "+err.Error())
+	}
+	if !oneOf(c.Log.Format, "json", "text") {
+		problems = append(problems, fmt.Sprintf("log.format %q must be json or text", c.Log.Format))
+	}
+	if !oneOf(c.Log.Output, "file", "stderr", "none") {
+		problems = append(problems, fmt.Sprintf("log.output %q must be file, stderr or none", c.Log.Output))
+	}
+	if strings.EqualFold(c.Log.Output, "file") && c.Log.Dir == "" {
+		problems = append(problems, "log.dir must not be empty when logging to a file")
+	}
+	if c.Log.MaxSizeMB < 0 || c.Log.MaxBackups < 0 || c.Log.MaxAgeDays < 0 {
+		problems = append(problems, "log.max_size_mb, log.max_backups and log.max_age_days cannot be negative")
+	}
+	if c.Log.TraceSample < 1 {
+		problems = append(problems, "log.trace_sample must be at least 1")
+	}
+	if !oneOf(c.Tracing.Exporter, "none", "stdout", "otlp") {
+		problems = append(problems, fmt.Sprintf("tracing.exporter %q must be none, stdout or otlp", c.Tracing.Exporter))
+	}
+	if strings.EqualFold(c.Tracing.Exporter, "otlp") && c.Tracing.Endpoint == "" {
+		problems = append(problems, "tracing.endpoint must not be empty when exporting to otlp")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems = append(problems, "tracing.sample_ratio must be between 0 and 1")
+	}
+	if !oneOf(c.TUI.Theme, "light", "dark", "high-contrast") {
+		problems = append(problems, fmt.Sprintf("tui.theme %q must be light, dark or high-contrast", c.TUI.Theme))
+	}
+	for action, keys := range c.TUI.Keys {
+		if len(keys) == 0 {
+			problems = append(problems, "tui.keys."+action+" needs at least one key")
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Duration is a time.Duration written as "10s" or "1m30s" in files, the
+// environment and flags.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func oneOf(v string, allowed ...string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(v, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// validOrigin reports whether origin is "*" or an http(s) scheme and host,
+// where the host may start with a "*." wildcard for any subdomain.
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+	if err != nil || !oneOf(u.Scheme, "http", "https") {
+		return false
+	}
+	return u.Host != "" && !strings.Contains(u.Host, "*") && (u.Path == "" || u.Path == "/") && u.RawQuery == "" && u.Fragment == ""
+}
+
+// Write prints the effective configuration as YAML, with secrets redacted.
+func (c *Config) Write(w io.Writer) error {
+	redacted := *c
+	redacted.Database.DSN = redactDSN(c.Database.DSN)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(redacted); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// redactDSN hides the password in both key=value and URL style DSNs.
+func redactDSN(dsn string) string {
+	fields := strings.Fields(dsn)
+	for i, f := range fields {
+		if strings.HasPrefix(f, "password=") {
+			fields[i] = "password=***"
+		}
+	}
+	dsn = strings.Join(fields, " ")
+
+	if scheme := strings.Index(dsn, "://"); scheme != -1 {
+		rest := dsn[scheme+3:]
+		if at := strings.Index(rest, "@"); at != -1 {
+			if colon := strings.Index(rest[:at], ":"); colon != -1 {
+				dsn = dsn[:scheme+3] + rest[:colon] + ":***" + rest[at:]
+			}
+		}
+	}
+	return dsn
+}
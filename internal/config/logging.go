@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// SetupLogging creates the logger described by cfg. Nothing is logged or
+// created on disk until this is called.
+func SetupLogging(cfg LogConfig) (*slog.Logger, error) {
+	level, err := parseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var w io.Writer
+	switch strings.ToLower(cfg.Output) {
+	case "file":
+		w, err = NewRotatingFile(cfg.Dir, int64(cfg.MaxSizeMB)*1024*1024, cfg.MaxBackups, cfg.MaxAgeDays)
+		if err != nil {
+			return nil, err
+		}
+	case "stderr":
+		w = os.Stderr
+	case "none":
+		w = io.Discard
+	default:
+		return nil, fmt.Errorf("unknown log output %q, use file, stderr or none", cfg.Output)
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "json":
+		handler = slog.NewJSONHandler(w, handlerOptions)
+	case "text":
+		handler = slog.NewTextHandler(w, handlerOptions)
+	default:
+		return nil, fmt.Errorf("unknown log format %q, use json or text", cfg.Format)
+	}
+
+	logger := slog.New(handler)
+
+	// Log the setup completion
+	logger.Debug("Logging setup complete", "level", level, "format", cfg.Format, "output", cfg.Output)
+	return logger, nil
+}
+
+func parseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return level, fmt.Errorf("unknown log level %q, use debug, info, warn or error", s)
+	}
+	return level, nil
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFile is an io.Writer that writes to <dir>/YYYY-MM-DD.log. The file
+// is rotated when it would grow beyond maxSize bytes or when the date
+// changes, after which old log files are pruned.
+type RotatingFile struct {
+	mu         sync.Mutex
+	dir        string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	now        func() time.Time
+
+	file *os.File
+	day  string
+	size int64
+}
+
+// NewRotatingFile opens the log file for today in dir, creating dir if
+// needed. A zero maxSize disables size-based rotation, a zero maxBackups
+// keeps every rotated file and a zero maxAgeDays keeps files forever.
+func NewRotatingFile(dir string, maxSize int64, maxBackups int, maxAgeDays int) (*RotatingFile, error) {
+	return newRotatingFile(dir, maxSize, maxBackups, maxAgeDays, time.Now)
+}
+
+func newRotatingFile(dir string, maxSize int64, maxBackups int, maxAgeDays int, now func() time.Time) (*RotatingFile, error) {
+	// Create logs directory if it doesn't exist
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+
+	r := &RotatingFile{
+		dir:        dir,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		maxAge:     time.Duration(maxAgeDays) * 24 * time.Hour,
+		now:        now,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write implements io.Writer.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.now().Format(time.DateOnly) != r.day {
+		if err := r.file.Close(); err != nil {
+			return 0, err
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+		r.prune()
+	} else if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current log file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+func (r *RotatingFile) path() string {
+	return filepath.Join(r.dir, r.day+".log")
+}
+
+func (r *RotatingFile) open() error {
+	r.day = r.now().Format(time.DateOnly)
+	file, err := os.OpenFile(r.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+// rotate moves the current file aside as YYYY-MM-DD.HHMMSS[.n].log and starts
+// a new one.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	base := filepath.Join(r.dir, r.day+"."+r.now().Format("150405"))
+	backup := base + ".log"
+	for i := 1; fileExists(backup); i++ {
+		backup = fmt.Sprintf("%s.%d.log", base, i)
+	}
+	if err := os.Rename(r.path(), backup); err != nil {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+	r.prune()
+	return nil
+}
+
+// prune removes log files beyond the retention limits. The active file is
+// never removed.
+func (r *RotatingFile) prune() {
+	if r.maxBackups <= 0 && r.maxAge <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return
+	}
+
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	var backups []backup
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".log") {
+			continue
+		}
+		path := filepath.Join(r.dir, entry.Name())
+		if path == r.path() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: path, modTime: info.ModTime()})
+	}
+
+	// Newest first
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+
+	cutoff := r.now().Add(-r.maxAge)
+	for i, b := range backups {
+		if (r.maxBackups > 0 && i >= r.maxBackups) || (r.maxAge > 0 && b.modTime.Before(cutoff)) {
+			_ = os.Remove(b.path)
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
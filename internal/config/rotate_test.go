@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+
+	clock := time.Date(2025, 2, 25, 10, 0, 0, 0, time.UTC)
+	r, err := newRotatingFile(dir, 10, 2, 0, func() time.Time { return clock })
+	if err != nil {
+		t.Fatalf("newRotatingFile returned an error: %v", err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	// Every write after the first overflows the 10 byte limit and rotates
+	for i := 0; i < 5; i++ {
+		if _, err := r.Write([]byte("12345678\n")); err != nil {
+			t.Fatalf("Write returned an error: %v", err)
+		}
+		clock = clock.Add(time.Second)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir returned an error: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	// The active file plus at most two backups survive pruning
+	if len(names) != 3 {
+		t.Errorf("found log files %v; expected the active file and 2 backups", names)
+	}
+	if !strings.Contains(strings.Join(names, " "), "2025-02-25.log") {
+		t.Errorf("found log files %v; expected the active file 2025-02-25.log", names)
+	}
+}
+
+func TestSetupLoggingRejectsUnknownOutput(t *testing.T) {
+	cfg := Default().Log
+	cfg.Output = "syslog"
+
+	if _, err := SetupLogging(cfg); err == nil {
+		t.Error("SetupLogging accepted an unknown output")
+	}
+}
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// setting binds one configuration value to its environment variable and
+// command-line flag.
+type setting struct {
+	env   string
+	flag  string
+	key   string // in the configuration file, e.g. tui.theme
+	usage string
+	set   func(c *Config, v string) error
+
+	// isBool flags may be given without a value, e.g. --tracing-insecure.
+	isBool bool
+}
+
+// stringSetting creates a setting for a plain string field.
+func stringSetting(env, flag, usage string, field func(c *Config) *string) setting {
+	return setting{
+		env:   env,
+		flag:  flag,
+		key:   fileKey(func(c *Config) any { return field(c) }),
+		usage: usage,
+		set: func(c *Config, v string) error {
+			*field(c) = v
+			return nil
+		},
+	}
+}
+
+// intSetting creates a setting for an integer field.
+func intSetting(env, flag, usage string, field func(c *Config) *int) setting {
+	return setting{
+		env:   env,
+		flag:  flag,
+		key:   fileKey(func(c *Config) any { return field(c) }),
+		usage: usage,
+		set: func(c *Config, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s: %q is not a whole number", flag, v)
+			}
+			*field(c) = n
+			return nil
+		},
+	}
+}
+
+// boolSetting creates a setting for a boolean field.
+func boolSetting(env, flag, usage string, field func(c *Config) *bool) setting {
+	return setting{
+		env:    env,
+		flag:   flag,
+		key:    fileKey(func(c *Config) any { return field(c) }),
+		usage:  usage,
+		isBool: true,
+		set: func(c *Config, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("%s: %q is not true or false", flag, v)
+			}
+			*field(c) = b
+			return nil
+		},
+	}
+}
+
+// floatSetting creates a setting for a floating point field.
+func floatSetting(env, flag, usage string, field func(c *Config) *float64) setting {
+	return setting{
+		env:   env,
+		flag:  flag,
+		key:   fileKey(func(c *Config) any { return field(c) }),
+		usage: usage,
+		set: func(c *Config, v string) error {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %q is not a number", flag, v)
+			}
+			*field(c) = f
+			return nil
+		},
+	}
+}
+
+// durationSetting creates a setting for a Duration field such as "10s".
+func durationSetting(env, flag, usage string, field func(c *Config) *Duration) setting {
+	return setting{
+		env:   env,
+		flag:  flag,
+		key:   fileKey(func(c *Config) any { return field(c) }),
+		usage: usage,
+		set: func(c *Config, v string) error {
+			if err := field(c).UnmarshalText([]byte(v)); err != nil {
+				return fmt.Errorf("%s: %q is not a duration such as 10s", flag, v)
+			}
+			return nil
+		},
+	}
+}
+
+// listSetting creates a setting for a comma-separated list.
+func listSetting(env, flag, usage string, field func(c *Config) *[]string) setting {
+	return setting{
+		env:   env,
+		flag:  flag,
+		key:   fileKey(func(c *Config) any { return field(c) }),
+		usage: usage,
+		set: func(c *Config, v string) error {
+			var items []string
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			*field(c) = items
+			return nil
+		},
+	}
+}
+
+// fileKey returns the key of the field that field points to in a
+// configuration file, its YAML names joined with dots.
+func fileKey(field func(c *Config) any) string {
+	var c Config
+	target := reflect.ValueOf(field(&c))
+	var find func(v reflect.Value, prefix string) string
+	find = func(v reflect.Value, prefix string) string {
+		for i := 0; i < v.NumField(); i++ {
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			f := v.Field(i)
+			// A struct shares its address with its first field, so the
+			// type has to match as well
+			if f.Addr().Pointer() == target.Pointer() && f.Addr().Type() == target.Type() {
+				return name
+			}
+			if f.Kind() == reflect.Struct {
+				if key := find(f, name); key != "" {
+					return key
+				}
+			}
+		}
+		return ""
+	}
+	return find(reflect.ValueOf(&c).Elem(), "")
+}
+
+var settings = []setting{
+	stringSetting("WBLANG", "locale", "dictionary locale, e.g. af-za",
+		func(c *Config) *string { return &c.Locale }),
+	stringSetting("WOORDSOEK_DICTIONARY_DIR", "dictionary-dir", "directory containing the <locale>.txt dictionaries",
+		func(c *Config) *string { return &c.DictionaryDir }),
+	stringSetting("WOORDSOEK_API_ADDR", "api-addr", "listen address of the API server",
+		func(c *Config) *string { return &c.API.Addr }),
+	stringSetting("WOORDSOEK_API_DEFAULT_LOCALE", "api-default-locale", "locale used by the API when no x-locale header is sent",
+		func(c *Config) *string { return &c.API.DefaultLocale }),
+	listSetting("WOORDSOEK_API_PRELOAD", "api-preload", "comma-separated locales to load before reporting ready, * for all",
+		func(c *Config) *[]string { return &c.API.Preload }),
+	durationSetting("WOORDSOEK_API_SHUTDOWN_TIMEOUT", "api-shutdown-timeout", "time allowed for in-flight requests on shutdown",
+		func(c *Config) *Duration { return &c.API.ShutdownTimeout }),
+	durationSetting("WOORDSOEK_API_CACHE_MAX_AGE", "api-cache-max-age", "how long clients may cache search responses, 0 to disable",
+		func(c *Config) *Duration { return &c.API.CacheMaxAge }),
+	boolSetting("WOORDSOEK_API_WATCH", "api-watch", "reload dictionaries when their files change",
+		func(c *Config) *bool { return &c.API.Watch }),
+	durationSetting("WOORDSOEK_API_LIVE_DEBOUNCE", "api-live-debounce", "quiet time before a live search runs",
+		func(c *Config) *Duration { return &c.API.LiveDebounce }),
+	boolSetting("WOORDSOEK_API_WEB_UI", "api-web-ui", "serve the web interface on /",
+		func(c *Config) *bool { return &c.API.WebUI }),
+	intSetting("WOORDSOEK_API_BODY_LIMIT", "api-body-limit", "maximum request body size in bytes",
+		func(c *Config) *int { return &c.API.BodyLimit }),
+	durationSetting("WOORDSOEK_API_READ_TIMEOUT", "api-read-timeout", "time allowed to read a request, 0 for no limit",
+		func(c *Config) *Duration { return &c.API.ReadTimeout }),
+	durationSetting("WOORDSOEK_API_WRITE_TIMEOUT", "api-write-timeout", "time allowed to write a response, 0 for no limit",
+		func(c *Config) *Duration { return &c.API.WriteTimeout }),
+	durationSetting("WOORDSOEK_API_IDLE_TIMEOUT", "api-idle-timeout", "time an idle keep-alive connection is kept open, 0 for no limit",
+		func(c *Config) *Duration { return &c.API.IdleTimeout }),
+	listSetting("WOORDSOEK_API_TRUSTED_PROXIES", "api-trusted-proxies", "comma-separated IPs or CIDR ranges of proxies whose X-Forwarded-For is trusted",
+		func(c *Config) *[]string { return &c.API.TrustedProxies }),
+	boolSetting("WOORDSOEK_API_HTTP2", "api-http2", "serve HTTP/2 next to HTTP/1.1",
+		func(c *Config) *bool { return &c.API.HTTP2 }),
+	stringSetting("WOORDSOEK_API_TLS_CERT_FILE", "api-tls-cert-file", "PEM certificate file, enables HTTPS",
+		func(c *Config) *string { return &c.API.TLS.CertFile }),
+	stringSetting("WOORDSOEK_API_TLS_KEY_FILE", "api-tls-key-file", "PEM private key file of the certificate",
+		func(c *Config) *string { return &c.API.TLS.KeyFile }),
+	boolSetting("WOORDSOEK_API_TLS_SELF_SIGNED", "api-tls-self-signed", "serve HTTPS with a generated certificate for local development",
+		func(c *Config) *bool { return &c.API.TLS.SelfSigned }),
+	listSetting("WOORDSOEK_API_CORS_ALLOW_ORIGINS", "api-cors-allow-origins", "comma-separated origins allowed to call the API from a browser, * for any",
+		func(c *Config) *[]string { return &c.API.CORS.AllowOrigins }),
+	durationSetting("WOORDSOEK_API_CORS_MAX_AGE", "api-cors-max-age", "how long browsers may cache CORS preflight responses",
+		func(c *Config) *Duration { return &c.API.CORS.MaxAge }),
+	intSetting("WOORDSOEK_API_BATCH_MAX_QUERIES", "api-batch-max-queries", "maximum number of queries in a batch search",
+		func(c *Config) *int { return &c.API.Batch.MaxQueries }),
+	intSetting("WOORDSOEK_API_BATCH_WORKERS", "api-batch-workers", "number of queries of a batch searched concurrently",
+		func(c *Config) *int { return &c.API.Batch.Workers }),
+	boolSetting("WOORDSOEK_API_AUTH_REQUIRED", "api-auth-required", "reject API requests without a valid key",
+		func(c *Config) *bool { return &c.API.Auth.Required }),
+	stringSetting("WOORDSOEK_API_AUTH_KEYS_FILE", "api-auth-keys-file", "YAML file with hashed API keys",
+		func(c *Config) *string { return &c.API.Auth.KeysFile }),
+	intSetting("WOORDSOEK_API_RATE_LIMIT_PER_KEY", "api-rate-limit-per-key", "requests per minute per API key, 0 to disable",
+		func(c *Config) *int { return &c.API.RateLimit.PerKey.RequestsPerMinute }),
+	intSetting("WOORDSOEK_API_RATE_LIMIT_PER_KEY_BURST", "api-rate-limit-per-key-burst", "burst size per API key",
+		func(c *Config) *int { return &c.API.RateLimit.PerKey.Burst }),
+	intSetting("WOORDSOEK_API_RATE_LIMIT_PER_IP", "api-rate-limit-per-ip", "requests per minute per client IP, 0 to disable",
+		func(c *Config) *int { return &c.API.RateLimit.PerIP.RequestsPerMinute }),
+	intSetting("WOORDSOEK_API_RATE_LIMIT_PER_IP_BURST", "api-rate-limit-per-ip-burst", "burst size per client IP",
+		func(c *Config) *int { return &c.API.RateLimit.PerIP.Burst }),
+	intSetting("WOORDSOEK_CACHE_SIZE", "cache-size", "number of search results kept in memory, 0 to disable",
+		func(c *Config) *int { return &c.Cache.Size }),
+	stringSetting("WOORDSOEK_DATABASE_DSN", "database-dsn", "PostgreSQL connection string used by the importer",
+		func(c *Config) *string { return &c.Database.DSN }),
+	stringSetting("WOORDSOEK_LOG_LEVEL", "log-level", "minimum log level: debug, info, warn or error",
+		func(c *Config) *string { return &c.Log.Level }),
+	stringSetting("WOORDSOEK_LOG_FORMAT", "log-format", "log format: json or text",
+		func(c *Config) *string { return &c.Log.Format }),
+	stringSetting("WOORDSOEK_LOG_OUTPUT", "log-output", "log destination: file, stderr or none",
+		func(c *Config) *string { return &c.Log.Output }),
+	stringSetting("WOORDSOEK_LOG_DIR", "log-dir", "directory for log files",
+		func(c *Config) *string { return &c.Log.Dir }),
+	intSetting("WOORDSOEK_LOG_MAX_SIZE_MB", "log-max-size-mb", "rotate log files at this size in megabytes, 0 to disable",
+		func(c *Config) *int { return &c.Log.MaxSizeMB }),
+	intSetting("WOORDSOEK_LOG_MAX_BACKUPS", "log-max-backups", "number of old log files to keep, 0 to keep all",
+		func(c *Config) *int { return &c.Log.MaxBackups }),
+	intSetting("WOORDSOEK_LOG_MAX_AGE_DAYS", "log-max-age-days", "days to keep old log files, 0 to keep forever",
+		func(c *Config) *int { return &c.Log.MaxAgeDays }),
+	intSetting("WOORDSOEK_LOG_TRACE_SAMPLE", "log-trace-sample", "log one in every n scanned words at debug level",
+		func(c *Config) *int { return &c.Log.TraceSample }),
+	stringSetting("WOORDSOEK_TRACING_EXPORTER", "tracing-exporter", "span exporter: none, stdout or otlp",
+		func(c *Config) *string { return &c.Tracing.Exporter }),
+	stringSetting("WOORDSOEK_TRACING_ENDPOINT", "tracing-endpoint", "OTLP gRPC collector endpoint",
+		func(c *Config) *string { return &c.Tracing.Endpoint }),
+	boolSetting("WOORDSOEK_TRACING_INSECURE", "tracing-insecure", "connect to the OTLP collector without TLS",
+		func(c *Config) *bool { return &c.Tracing.Insecure }),
+	floatSetting("WOORDSOEK_TRACING_SAMPLE_RATIO", "tracing-sample-ratio", "fraction of traces to record, 0 to 1",
+		func(c *Config) *float64 { return &c.Tracing.SampleRatio }),
+	stringSetting("WOORDSOEK_TUI_THEME", "tui-theme", "TUI colours: light, dark or high-contrast",
+		func(c *Config) *string { return &c.TUI.Theme }),
+}
@@ -1,13 +1,97 @@
 package errors
 
-import "fmt"
+import (
+	stderrors "errors"
+	"fmt"
+)
+
+// Code classifies an error so that callers (API, gRPC, TUI) can react to it
+// without having to inspect the message text.
+type Code string
+
+const (
+	CodeInternal           Code = "internal"
+	CodeDictionaryNotFound Code = "dictionary_not_found"
+	CodeDictionaryCorrupt  Code = "dictionary_corrupt"
+	CodeInvalidQuery       Code = "invalid_query"
+	CodeCancelled          Code = "cancelled"
+	CodeUnauthorized       Code = "unauthorized"
+	CodeForbidden          Code = "forbidden"
+	CodeRateLimited        Code = "rate_limited"
+	CodeBadRequest         Code = "bad_request"
+	CodeNotFound           Code = "not_found"
+	CodeMethodNotAllowed   Code = "method_not_allowed"
+	CodeNotAcceptable      Code = "not_acceptable"
+	CodeUpgradeRequired    Code = "upgrade_required"
+)
+
+// Sentinel errors for use with errors.Is. Any CustomError carrying the same
+// Code matches the sentinel, regardless of its message or wrapped cause.
+var (
+	ErrDictionaryNotFound = &CustomError{Code: CodeDictionaryNotFound, Message: "dictionary not found"}
+	ErrDictionaryCorrupt  = &CustomError{Code: CodeDictionaryCorrupt, Message: "dictionary could not be read"}
+	ErrInvalidQuery       = &CustomError{Code: CodeInvalidQuery, Message: "invalid query"}
+	ErrCancelled          = &CustomError{Code: CodeCancelled, Message: "search cancelled"}
+	ErrUnauthorized       = &CustomError{Code: CodeUnauthorized, Message: "a valid API key is required"}
+	ErrForbidden          = &CustomError{Code: CodeForbidden, Message: "the API key may not do this"}
+	ErrRateLimited        = &CustomError{Code: CodeRateLimited, Message: "rate limit exceeded"}
+)
 
 // CustomError defines a custom error type for the application.
 type CustomError struct {
-	Message string
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	Err     error  `json:"-"`
+}
+
+// New creates a CustomError with the given code and message.
+func New(code Code, message string) *CustomError {
+	return &CustomError{Code: code, Message: message}
+}
+
+// Wrap creates a CustomError with the given code and message that wraps err.
+func Wrap(code Code, message string, err error) *CustomError {
+	return &CustomError{Code: code, Message: message, Err: err}
 }
 
 // Error implements the error interface for CustomError.
 func (e *CustomError) Error() string {
-	return fmt.Sprintf("CustomError: %s", e.Message)
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying cause, if any.
+func (e *CustomError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is a CustomError with the same code.
+func (e *CustomError) Is(target error) bool {
+	t, ok := target.(*CustomError)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code
+}
+
+// CodeOf returns the code of the first CustomError in err's chain, or
+// CodeInternal when there is none.
+func CodeOf(err error) Code {
+	var ce *CustomError
+	if stderrors.As(err, &ce) && ce.Code != "" {
+		return ce.Code
+	}
+	return CodeInternal
+}
+
+// Is and As mirror the standard library so that packages importing this one
+// as "errors" do not also need the standard errors package.
+func Is(err, target error) bool {
+	return stderrors.Is(err, target)
+}
+
+func As(err error, target any) bool {
+	return stderrors.As(err, target)
 }
@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCustomErrorIs(t *testing.T) {
+	err := fmt.Errorf("loading: %w", Wrap(CodeDictionaryNotFound, "no such dictionary", io.EOF))
+
+	if !Is(err, ErrDictionaryNotFound) {
+		t.Errorf("Is(%v, ErrDictionaryNotFound) = false; expected true", err)
+	}
+	if Is(err, ErrInvalidQuery) {
+		t.Errorf("Is(%v, ErrInvalidQuery) = true; expected false", err)
+	}
+	if !Is(err, io.EOF) {
+		t.Errorf("Is(%v, io.EOF) = false; expected the cause to be unwrapped", err)
+	}
+
+	var ce *CustomError
+	if !As(err, &ce) || ce.Message != "no such dictionary" {
+		t.Errorf("As(%v) did not return the wrapped CustomError", err)
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected Code
+	}{
+		{ErrCancelled, CodeCancelled},
+		{fmt.Errorf("wrapped: %w", ErrDictionaryCorrupt), CodeDictionaryCorrupt},
+		{io.EOF, CodeInternal},
+	}
+
+	for _, test := range tests {
+		if code := CodeOf(test.err); code != test.expected {
+			t.Errorf("CodeOf(%v) = %q; expected %q", test.err, code, test.expected)
+		}
+	}
+}
+
+func TestToGRPC(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected codes.Code
+	}{
+		{ErrDictionaryNotFound, codes.NotFound},
+		{fmt.Errorf("wrapped: %w", ErrInvalidQuery), codes.InvalidArgument},
+		{ErrCancelled, codes.Canceled},
+		{io.EOF, codes.Internal},
+	}
+
+	for _, test := range tests {
+		if code := status.Code(ToGRPC(test.err)); code != test.expected {
+			t.Errorf("status.Code(ToGRPC(%v)) = %v; expected %v", test.err, code, test.expected)
+		}
+	}
+}
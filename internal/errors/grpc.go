@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCCode maps an error code to the closest gRPC status code.
+func GRPCCode(code Code) codes.Code {
+	switch code {
+	case CodeDictionaryNotFound, CodeNotFound:
+		return codes.NotFound
+	case CodeDictionaryCorrupt:
+		return codes.DataLoss
+	case CodeInvalidQuery, CodeBadRequest, CodeNotAcceptable:
+		return codes.InvalidArgument
+	case CodeCancelled:
+		return codes.Canceled
+	case CodeUnauthorized:
+		return codes.Unauthenticated
+	case CodeForbidden:
+		return codes.PermissionDenied
+	case CodeRateLimited:
+		return codes.ResourceExhausted
+	case CodeMethodNotAllowed:
+		return codes.Unimplemented
+	case CodeUpgradeRequired:
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
+
+// GRPCStatus lets status.FromError and status.Code recognise a CustomError
+// returned from a gRPC handler without any explicit conversion.
+func (e *CustomError) GRPCStatus() *status.Status {
+	return status.New(GRPCCode(e.Code), e.Message)
+}
+
+// ToGRPC converts any error into a gRPC status error, using the code of the
+// first CustomError in the chain.
+func ToGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+	var ce *CustomError
+	if As(err, &ce) {
+		return ce.GRPCStatus().Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
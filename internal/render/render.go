@@ -0,0 +1,159 @@
+// Package render writes search results in the formats offered by the API
+// and the command line: JSON, NDJSON, CSV and plain text.
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+// Format is an output format.
+type Format string
+
+const (
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+	CSV    Format = "csv"
+	Text   Format = "text"
+)
+
+// formats lists the formats in order of preference, with their media types.
+var formats = []struct {
+	format    Format
+	mediaType string
+}{
+	{JSON, "application/json"},
+	{NDJSON, "application/x-ndjson"},
+	{CSV, "text/csv"},
+	{Text, "text/plain"},
+}
+
+// ParseFormat returns the Format named s; an empty s is JSON.
+func ParseFormat(s string) (Format, error) {
+	if s == "" {
+		return JSON, nil
+	}
+	for _, f := range formats {
+		if Format(strings.ToLower(s)) == f.format {
+			return f.format, nil
+		}
+	}
+	return "", errors.New(errors.CodeInvalidQuery, "format must be json, ndjson, csv or text")
+}
+
+// MediaTypes returns the media types of every format, JSON first, for
+// content negotiation.
+func MediaTypes() []string {
+	types := make([]string, len(formats))
+	for i, f := range formats {
+		types[i] = f.mediaType
+	}
+	return types
+}
+
+// ForMediaType returns the format with the given media type.
+func ForMediaType(mediaType string) (Format, bool) {
+	for _, f := range formats {
+		if f.mediaType == mediaType {
+			return f.format, true
+		}
+	}
+	return "", false
+}
+
+// ContentType returns the Content-Type header value for f.
+func (f Format) ContentType() string {
+	for _, ft := range formats {
+		if ft.format == f {
+			if strings.HasPrefix(ft.mediaType, "text/") {
+				return ft.mediaType + "; charset=utf-8"
+			}
+			return ft.mediaType
+		}
+	}
+	return "application/octet-stream"
+}
+
+// Word is a result word with the details listed by the NDJSON and CSV
+// formats.
+type Word struct {
+	Word    string `json:"word"`
+	Length  int    `json:"length"`
+	Score   int    `json:"score"`
+	Pangram bool   `json:"pangram"`
+}
+
+// Words describes each of words as a result of q.
+func Words(words []string, q woordsoek.Query) []Word {
+	described := make([]Word, len(words))
+	for i, word := range words {
+		described[i] = Word{
+			Word:    word,
+			Length:  utf8.RuneCountInString(word),
+			Score:   woordsoek.Score(word, q),
+			Pangram: woordsoek.IsPangram(word, q),
+		}
+	}
+	return described
+}
+
+// Results is what gets rendered: the words found for Query. Document is the
+// value written by the JSON format, such as an API response with paging
+// details; when it is nil the JSON format writes the described words. The
+// other formats always list the words only.
+type Results struct {
+	Query    woordsoek.Query
+	Words    []string
+	Document any
+}
+
+// Render writes r to w in format f.
+func Render(w io.Writer, f Format, r Results) error {
+	switch f {
+	case JSON:
+		doc := r.Document
+		if doc == nil {
+			doc = Words(r.Words, r.Query)
+		}
+		return json.NewEncoder(w).Encode(doc)
+
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		for _, word := range Words(r.Words, r.Query) {
+			if err := enc.Encode(word); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case CSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"word", "length", "score", "pangram"}); err != nil {
+			return err
+		}
+		for _, word := range Words(r.Words, r.Query) {
+			record := []string{word.Word, strconv.Itoa(word.Length), strconv.Itoa(word.Score), strconv.FormatBool(word.Pangram)}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case Text:
+		for _, word := range r.Words {
+			if _, err := io.WriteString(w, word+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return errors.New(errors.CodeInvalidQuery, "unknown format "+string(f))
+}
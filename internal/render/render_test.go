@@ -0,0 +1,65 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+func TestRender(t *testing.T) {
+	results := Results{
+		Query: woordsoek.Query{SingleLetter: "o", SixCharString: "aedr"},
+		Words: []string{"adore", "road"},
+	}
+
+	tests := []struct {
+		format   Format
+		document any
+		expected string
+	}{
+		{JSON, nil, `[{"word":"adore","length":5,"score":12,"pangram":true},{"word":"road","length":4,"score":1,"pangram":false}]` + "\n"},
+		{JSON, map[string]int{"count": 2}, `{"count":2}` + "\n"},
+		{NDJSON, nil, `{"word":"adore","length":5,"score":12,"pangram":true}` + "\n" + `{"word":"road","length":4,"score":1,"pangram":false}` + "\n"},
+		{CSV, nil, "word,length,score,pangram\nadore,5,12,true\nroad,4,1,false\n"},
+		{Text, map[string]int{"count": 2}, "adore\nroad\n"},
+	}
+	for _, test := range tests {
+		var b bytes.Buffer
+		results.Document = test.document
+		if err := Render(&b, test.format, results); err != nil {
+			t.Errorf("Render(%s) returned an error: %v", test.format, err)
+			continue
+		}
+		if b.String() != test.expected {
+			t.Errorf("Render(%s) = %q; expected %q", test.format, b.String(), test.expected)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Format
+		err      error
+	}{
+		{"", JSON, nil},
+		{"CSV", CSV, nil},
+		{"ndjson", NDJSON, nil},
+		{"xml", "", errors.ErrInvalidQuery},
+	}
+	for _, test := range tests {
+		format, err := ParseFormat(test.name)
+		if format != test.expected || !errors.Is(err, test.err) {
+			t.Errorf("ParseFormat(%q) = %q, %v; expected %q, %v", test.name, format, err, test.expected, test.err)
+		}
+	}
+
+	if ct := Text.ContentType(); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Text.ContentType() = %q; expected text/plain; charset=utf-8", ct)
+	}
+	if f, ok := ForMediaType("application/x-ndjson"); !ok || f != NDJSON {
+		t.Errorf("ForMediaType(application/x-ndjson) = %q, %v; expected ndjson", f, ok)
+	}
+}
@@ -0,0 +1,40 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogHandler adds the trace and span IDs of the active span to every record
+// logged with a context, e.g. slog.InfoContext(ctx, ...).
+type LogHandler struct {
+	slog.Handler
+}
+
+// NewLogger wraps the handler of logger in a LogHandler.
+func NewLogger(logger *slog.Logger) *slog.Logger {
+	return slog.New(LogHandler{Handler: logger.Handler()})
+}
+
+// Handle implements slog.Handler.
+func (h LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler.
+func (h LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return LogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler.
+func (h LogHandler) WithGroup(name string) slog.Handler {
+	return LogHandler{Handler: h.Handler.WithGroup(name)}
+}
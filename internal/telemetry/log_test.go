@@ -0,0 +1,41 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"go.opentelemetry.io/otel"
+)
+
+func TestLogHandlerAddsTraceIDs(t *testing.T) {
+	shutdown, err := SetupTracing(context.Background(), "test", config.Default().Tracing)
+	if err != nil {
+		t.Fatalf("SetupTracing returned an error: %v", err)
+	}
+	defer func() {
+		_ = shutdown(context.Background())
+	}()
+
+	var buf bytes.Buffer
+	logger := NewLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "test")
+	logger.InfoContext(ctx, "inside span")
+	span.End()
+	logger.Info("outside span")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logged %d lines; expected 2", len(lines))
+	}
+	if expected := "trace_id=" + span.SpanContext().TraceID().String(); !strings.Contains(lines[0], expected) {
+		t.Errorf("record %q does not contain %s", lines[0], expected)
+	}
+	if strings.Contains(lines[1], "trace_id") {
+		t.Errorf("record %q logged outside a span has a trace_id", lines[1])
+	}
+}
@@ -0,0 +1,267 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jvanrhyn/woordsoek/internal/render"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+// exportFormats are the file extensions results can be exported as, in the
+// order tab cycles through them.
+var exportFormats = []string{".txt", ".csv", ".json", ".md"}
+
+// export is a set of results to be written to a file, with what was
+// searched for so that the file makes sense on its own.
+type export struct {
+	Query    woordsoek.Query
+	Filter   string
+	Words    []string
+	Exported time.Time
+}
+
+// exportedMsg reports the outcome of writing an export to path.
+type exportedMsg struct {
+	path  string
+	words int
+	err   error
+}
+
+// defaultExportName names an export after the locale and the letters
+// searched for, such as woordsoek-af-za-o-aedor.txt.
+func defaultExportName(q woordsoek.Query) string {
+	parts := []string{"woordsoek", q.Locale, q.SingleLetter}
+	if q.SixCharString != "" {
+		parts = append(parts, q.SixCharString)
+	}
+	if q.Length > 0 {
+		parts = append(parts, strconv.Itoa(q.Length))
+	}
+	name := strings.ToLower(strings.Join(parts, "-"))
+	// The letters are typed by the user, keep them from naming a directory
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, name)
+	return name + exportFormats[0]
+}
+
+// nextExportFormat swaps the extension of name for the next export format.
+func nextExportFormat(name string) string {
+	ext := filepath.Ext(name)
+	for i, format := range exportFormats {
+		if strings.EqualFold(ext, format) {
+			return strings.TrimSuffix(name, ext) + exportFormats[(i+1)%len(exportFormats)]
+		}
+	}
+	return name + exportFormats[0]
+}
+
+// writeExport writes e to w in the format named by ext.
+func writeExport(w io.Writer, ext string, e export) error {
+	header := [][2]string{
+		{"Dictionary", woordsoek.LocaleName(e.Query.Locale) + " (" + e.Query.Locale + ")"},
+		{"Single letter", e.Query.SingleLetter},
+		{"Letters", e.Query.SixCharString},
+		{"Word length", "any"},
+	}
+	if e.Query.Length > 0 {
+		header[3][1] = strconv.Itoa(e.Query.Length)
+	}
+	if e.Filter != "" {
+		header = append(header, [2]string{"Filter", e.Filter})
+	}
+	header = append(header,
+		[2]string{"Words", strconv.Itoa(len(e.Words))},
+		[2]string{"Exported", e.Exported.Format(time.RFC3339)},
+	)
+
+	switch strings.ToLower(ext) {
+	case ".txt", ".csv":
+		// CSV readers can skip the header as comments
+		for _, field := range header {
+			if _, err := fmt.Fprintf(w, "# %s: %s\n", field[0], field[1]); err != nil {
+				return err
+			}
+		}
+		format := render.Text
+		if strings.EqualFold(ext, ".csv") {
+			format = render.CSV
+		} else if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+		return render.Render(w, format, render.Results{Query: e.Query, Words: e.Words})
+
+	case ".json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Locale        string        `json:"locale"`
+			SingleLetter  string        `json:"singleLetter"`
+			SixCharString string        `json:"sixCharString"`
+			Length        int           `json:"length,omitempty"`
+			Filter        string        `json:"filter,omitempty"`
+			Exported      time.Time     `json:"exported"`
+			Count         int           `json:"count"`
+			Words         []render.Word `json:"words"`
+		}{
+			Locale:        e.Query.Locale,
+			SingleLetter:  e.Query.SingleLetter,
+			SixCharString: e.Query.SixCharString,
+			Length:        e.Query.Length,
+			Filter:        e.Filter,
+			Exported:      e.Exported,
+			Count:         len(e.Words),
+			Words:         render.Words(e.Words, e.Query),
+		})
+
+	case ".md":
+		var b strings.Builder
+		b.WriteString("# Woordsoek results\n\n| | |\n|---|---|\n")
+		for _, field := range header {
+			fmt.Fprintf(&b, "| %s | %s |\n", field[0], markdownEscape(field[1]))
+		}
+		b.WriteString("\n| Word | Length | Score | Pangram |\n|---|---:|---:|---|\n")
+		for _, word := range render.Words(e.Words, e.Query) {
+			pangram := ""
+			if word.Pangram {
+				pangram = "yes"
+			}
+			fmt.Fprintf(&b, "| %s | %d | %d | %s |\n", markdownEscape(word.Word), word.Length, word.Score, pangram)
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	return fmt.Errorf("files ending in %q cannot be exported, use one of %s", ext, strings.Join(exportFormats, ", "))
+}
+
+// markdownEscape keeps s from breaking out of a table cell.
+func markdownEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`).Replace(s)
+}
+
+// saveExport writes e to path in the format named by its extension. An
+// existing file is only replaced when overwrite is set.
+func saveExport(path string, e export, overwrite bool) tea.Cmd {
+	return func() tea.Msg {
+		err := writeExportFile(path, e, overwrite)
+		return exportedMsg{path: path, words: len(e.Words), err: err}
+	}
+}
+
+func writeExportFile(path string, e export, overwrite bool) error {
+	// Render first, so that an unknown format leaves no file behind
+	var b bytes.Buffer
+	if err := writeExport(&b, filepath.Ext(path), e); err != nil {
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := b.WriteTo(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// newExportInput creates the field the export file name is typed into.
+func newExportInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "Export to: "
+	input.CharLimit = 255
+	return input
+}
+
+// openExport asks where to export the results that pass the filter.
+func (m Model) openExport() (Model, tea.Cmd) {
+	m.currentState = exporting
+	m.exportError = ""
+	m.overwrite = false
+	m.exportInput.SetValue(defaultExportName(m.query))
+	m.exportInput.CursorEnd()
+	return m, m.exportInput.Focus()
+}
+
+// updateExport handles a key pressed while the file name is asked for.
+func (m Model) updateExport(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.currentState = editing
+		m.exportInput.Blur()
+		return m, nil
+	case key.Matches(msg, m.keys.NextFormat):
+		m.exportInput.SetValue(nextExportFormat(m.exportInput.Value()))
+		m.exportInput.CursorEnd()
+		m.exportError, m.overwrite = "", false
+		return m, nil
+	case key.Matches(msg, m.keys.Confirm):
+		path := strings.TrimSpace(m.exportInput.Value())
+		if path == "" {
+			m.exportError = "Please type a file name."
+			return m, nil
+		}
+		var words []string
+		for _, item := range m.list.VisibleItems() {
+			words = append(words, string(item.(wordItem)))
+		}
+		e := export{Query: m.query, Words: words, Exported: time.Now()}
+		if m.list.IsFiltered() {
+			e.Filter = m.list.FilterValue()
+		}
+		return m, saveExport(path, e, m.overwrite)
+	}
+
+	value := m.exportInput.Value()
+	var cmd tea.Cmd
+	m.exportInput, cmd = m.exportInput.Update(msg)
+	if m.exportInput.Value() != value {
+		m.exportError, m.overwrite = "", false
+	}
+	return m, cmd
+}
+
+// exported shows the outcome of an export. A file that already exists is
+// only replaced when the confirm key is pressed again.
+func (m Model) exported(msg exportedMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		if m.currentState != exporting {
+			// The prompt was left before the export failed
+			slog.Error("Failed to export results", "path", msg.path, "error", msg.err)
+			return m, nil
+		}
+		if os.IsExist(msg.err) {
+			m.exportError = msg.path + " already exists. Press " + m.keys.Confirm.Help().Key + " again to replace it."
+			m.overwrite = true
+			return m, nil
+		}
+		slog.Error("Failed to export results", "path", msg.path, "error", msg.err)
+		m.exportError = "The results could not be exported: " + msg.err.Error()
+		return m, nil
+	}
+	if m.currentState == exporting {
+		m.currentState = editing
+		m.exportInput.Blur()
+	}
+	return m, m.list.NewStatusMessage("Exported " + strconv.Itoa(msg.words) + " words to " + msg.path + ".")
+}
@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+func TestDefaultExportName(t *testing.T) {
+	tests := []struct {
+		query    woordsoek.Query
+		expected string
+	}{
+		{woordsoek.Query{Locale: "af-za", SingleLetter: "o", SixCharString: "aedor"}, "woordsoek-af-za-o-aedor.txt"},
+		{woordsoek.Query{Locale: "en", SingleLetter: "E", SixCharString: "RSDTN", Length: 5}, "woordsoek-en-e-rsdtn-5.txt"},
+		{woordsoek.Query{Locale: "en", SingleLetter: "o", SixCharString: "a/b"}, "woordsoek-en-o-a_b.txt"},
+	}
+	for _, test := range tests {
+		if name := defaultExportName(test.query); name != test.expected {
+			t.Errorf("defaultExportName(%+v) = %q; expected %q", test.query, name, test.expected)
+		}
+	}
+}
+
+func TestNextExportFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"words.txt", "words.csv"},
+		{"words.csv", "words.json"},
+		{"words.json", "words.md"},
+		{"words.MD", "words.txt"},
+		{"words", "words.txt"},
+	}
+	for _, test := range tests {
+		if name := nextExportFormat(test.name); name != test.expected {
+			t.Errorf("nextExportFormat(%q) = %q; expected %q", test.name, name, test.expected)
+		}
+	}
+}
+
+func TestWriteExport(t *testing.T) {
+	e := export{
+		Query:    woordsoek.Query{Locale: "af-za", SingleLetter: "o", SixCharString: "aedr"},
+		Filter:   "do",
+		Words:    []string{"door", "adore"},
+		Exported: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		ext      string
+		contains []string
+	}{
+		{".txt", []string{"# Dictionary: Afrikaans (Suid-Afrika) (af-za)\n", "# Filter: do\n", "# Words: 2\n", "\n\ndoor\nadore\n"}},
+		{".csv", []string{"# Letters: aedr\n", "# Word length: any\n", "word,length,score,pangram\ndoor,4,1,false\nadore,5,12,true\n"}},
+		{".md", []string{"| Single letter | o |\n", "| door | 4 | 1 |  |\n", "| adore | 5 | 12 | yes |\n"}},
+	}
+	for _, test := range tests {
+		var b bytes.Buffer
+		if err := writeExport(&b, test.ext, e); err != nil {
+			t.Errorf("writeExport(%s) returned an error: %v", test.ext, err)
+			continue
+		}
+		for _, s := range test.contains {
+			if !strings.Contains(b.String(), s) {
+				t.Errorf("writeExport(%s) wrote\n%s\nexpected it to contain %q", test.ext, b.String(), s)
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	if err := writeExport(&b, ".json", e); err != nil {
+		t.Fatalf("writeExport(.json) returned an error: %v", err)
+	}
+	var doc struct {
+		Locale string `json:"locale"`
+		Filter string `json:"filter"`
+		Count  int    `json:"count"`
+		Words  []struct {
+			Word string `json:"word"`
+		} `json:"words"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("writeExport(.json) wrote undecodable JSON: %v", err)
+	}
+	if doc.Locale != "af-za" || doc.Filter != "do" || doc.Count != 2 || len(doc.Words) != 2 || doc.Words[1].Word != "adore" {
+		t.Errorf("writeExport(.json) wrote %+v", doc)
+	}
+
+	if err := writeExport(&b, ".xml", e); err == nil {
+		t.Error("writeExport(.xml) returned no error")
+	}
+}
+
+func TestWriteExportFile(t *testing.T) {
+	dir := t.TempDir()
+	e := export{Query: woordsoek.Query{Locale: "xx", SingleLetter: "o"}, Words: []string{"door"}}
+
+	path := filepath.Join(dir, "words.txt")
+	if err := writeExportFile(path, e, false); err != nil {
+		t.Fatalf("writeExportFile returned an error: %v", err)
+	}
+	if err := writeExportFile(path, e, false); !os.IsExist(err) {
+		t.Errorf("Exporting over an existing file returned %v; expected it to exist", err)
+	}
+	if err := writeExportFile(path, e, true); err != nil {
+		t.Errorf("Replacing an export returned an error: %v", err)
+	}
+
+	if err := writeExportFile(filepath.Join(dir, "words.xml"), e, false); err == nil {
+		t.Error("Exporting to an unknown format returned no error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "words.xml")); !os.IsNotExist(err) {
+		t.Error("Exporting to an unknown format left a file behind")
+	}
+}
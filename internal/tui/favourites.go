@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// recordMsg fires historyDelay after the results of search number seq are
+// shown.
+type recordMsg struct {
+	seq int
+}
+
+// favouriteItem is a starred search or, when word is set, a word starred in
+// the results of query.
+type favouriteItem struct {
+	word  string
+	query savedQuery
+}
+
+func (f favouriteItem) FilterValue() string {
+	return f.word + " " + f.query.String()
+}
+
+func (f favouriteItem) Title() string {
+	if f.word != "" {
+		return "★ " + f.word
+	}
+	return f.query.String()
+}
+
+func (f favouriteItem) Description() string {
+	if f.word != "" {
+		return f.query.Locale + " · found by " + f.query.String()
+	}
+	return f.query.Locale + " · " + strconv.Itoa(f.query.Results) + " words · starred " + f.query.Time.Format("2 Jan 2006")
+}
+
+// newFavourites creates the list of starred searches and words.
+func newFavourites() list.Model {
+	favourites := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	favourites.Title = "Favourites"
+	favourites.SetStatusBarItemName("favourite", "favourites")
+	// Esc goes back instead
+	favourites.DisableQuitKeybindings()
+	return favourites
+}
+
+// favouriteItems lists the starred searches followed by the starred words.
+func (m Model) favouriteItems() []list.Item {
+	items := make([]list.Item, 0, len(m.history.Queries)+len(m.history.Words))
+	for _, q := range m.history.Queries {
+		items = append(items, favouriteItem{query: q})
+	}
+	for _, w := range m.history.Words {
+		items = append(items, favouriteItem{word: w.Word, query: w.Query})
+	}
+	return items
+}
+
+// openFavourites shows the favourites until one is picked or the screen is
+// left with esc.
+func (m Model) openFavourites() (Model, tea.Cmd) {
+	m.currentState = browsingFavourites
+	m.favourites.ResetFilter()
+	return m, m.favourites.SetItems(m.favouriteItems())
+}
+
+func (m Model) updateFavourites(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && m.favourites.FilterState() != list.Filtering {
+		item, selected := m.favourites.SelectedItem().(favouriteItem)
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			if m.favourites.FilterState() == list.Unfiltered {
+				m.currentState = editing
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Confirm):
+			// A starred word shows the search it was found by
+			if selected {
+				m.currentState = editing
+				return m.recall(item.query).searchWords()
+			}
+		case key.Matches(msg, m.keys.Unstar):
+			if selected {
+				if item.word != "" {
+					m.history.toggleWord(item.word, item.query)
+					if item.query.Locale == m.query.Locale {
+						delete(m.starred, item.word)
+					}
+				} else {
+					m.history.toggleQuery(item.query)
+				}
+				m.saveHistory()
+				return m, m.favourites.SetItems(m.favouriteItems())
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.favourites, cmd = m.favourites.Update(msg)
+	return m, cmd
+}
+
+// recall puts the search s in the fields, in its dictionary.
+func (m Model) recall(s savedQuery) Model {
+	length := ""
+	if s.Length > 0 {
+		length = strconv.Itoa(s.Length)
+	}
+	m.inputs[singleLetterField].SetValue(s.SingleLetter)
+	m.inputs[sixCharStringField].SetValue(s.SixCharString)
+	m.inputs[lengthField].SetValue(length)
+	for i := range m.inputs {
+		m.inputs[i].CursorEnd()
+	}
+	m.locale = s.Locale
+	return m
+}
+
+// browseHistory puts an earlier search in the fields, older for a negative
+// step. Going past the latest search brings back what was being typed.
+func (m Model) browseHistory(step int) (Model, tea.Cmd) {
+	n := len(m.history.Searches)
+	i := m.historyIndex + step
+	if i < 0 || i > n || i == m.historyIndex {
+		return m, nil
+	}
+	if m.historyIndex == n {
+		m.draftLocale = m.locale
+		m.draftFields = make([]string, len(m.inputs))
+		for j := range m.inputs {
+			m.draftFields[j] = m.inputs[j].Value()
+		}
+	}
+	m.historyIndex = i
+
+	if i < n {
+		m = m.recall(m.history.Searches[i])
+	} else {
+		for j := range m.inputs {
+			m.inputs[j].SetValue(m.draftFields[j])
+			m.inputs[j].CursorEnd()
+		}
+		m.locale = m.draftLocale
+	}
+	return m.typed()
+}
+
+// record adds the search on screen to the history.
+func (m Model) record(msg recordMsg) Model {
+	if msg.seq != m.seq || m.loading || m.query.SingleLetter == "" {
+		return m
+	}
+	m.history.add(newSavedQuery(m.query, m.total))
+	m.historyIndex = len(m.history.Searches)
+	m.saveHistory()
+	return m
+}
+
+// starQuery stars the search on screen, or unstars it.
+func (m Model) starQuery() (Model, tea.Cmd) {
+	if m.query.SingleLetter == "" || m.loading {
+		return m, nil
+	}
+	s := newSavedQuery(m.query, m.total)
+	status := "Unstarred " + s.String() + "."
+	if m.history.toggleQuery(s) {
+		status = "Starred " + s.String() + "."
+	}
+	m.saveHistory()
+	return m, m.list.NewStatusMessage(status)
+}
+
+// starWord stars the word under the cursor in the results, or unstars it.
+func (m Model) starWord() (Model, tea.Cmd) {
+	item, ok := m.list.SelectedItem().(wordItem)
+	if !ok {
+		return m, nil
+	}
+	word := string(item)
+	if m.history.toggleWord(word, newSavedQuery(m.query, m.total)) {
+		m.starred[word] = true
+	} else {
+		delete(m.starred, word)
+	}
+	m.saveHistory()
+	return m, nil
+}
+
+// saveHistory writes the history, if there is somewhere to keep it.
+func (m Model) saveHistory() {
+	if m.historyPath == "" {
+		return
+	}
+	if err := m.history.save(m.historyPath); err != nil {
+		slog.Warn("Failed to save the search history", "path", m.historyPath, "error", err)
+	}
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+// hintsView draws hints the way the Spelling Bee hint page does: a grid of
+// word counts by first letter and length, and the two letter prefixes with
+// their counts, one line per first letter.
+func hintsView(h woordsoek.Hints) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Words: %d • Points: %d • Pangrams: %d\n\n", h.Words, h.Points, h.Pangrams)
+
+	cell := func(n int) string {
+		if n == 0 {
+			return fmt.Sprintf("%4s", "-")
+		}
+		return fmt.Sprintf("%4d", n)
+	}
+
+	b.WriteString("     ")
+	for _, n := range h.Lengths {
+		fmt.Fprintf(&b, "%4d", n)
+	}
+	b.WriteString("   Σ\n")
+	for _, row := range h.Grid {
+		fmt.Fprintf(&b, "  %s: ", row.Letter)
+		for _, count := range row.Counts {
+			b.WriteString(cell(count))
+		}
+		b.WriteString(cell(row.Total) + "\n")
+	}
+	b.WriteString("  Σ: ")
+	for _, total := range h.LengthTotals {
+		b.WriteString(cell(total))
+	}
+	b.WriteString(cell(h.Words) + "\n\n")
+
+	b.WriteString("Two letter list:\n")
+	first := ""
+	for _, p := range h.Prefixes {
+		letter, _ := utf8.DecodeRuneInString(p.Prefix)
+		if string(letter) != first {
+			if first != "" {
+				b.WriteString("\n")
+			}
+			first = string(letter)
+			b.WriteString(" ")
+		}
+		fmt.Fprintf(&b, " %s-%d", p.Prefix, p.Count)
+	}
+	if first != "" {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
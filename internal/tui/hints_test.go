@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+func TestHintsView(t *testing.T) {
+	q := woordsoek.Query{SingleLetter: "o", SixCharString: "aedr"}
+	view := hintsView(woordsoek.NewHints([]string{"adore", "door", "odor", "road", "rode"}, q))
+	expected := "" +
+		"Words: 5 • Points: 16 • Pangrams: 1\n" +
+		"\n" +
+		"        4   5   Σ\n" +
+		"  a:    -   1   1\n" +
+		"  d:    1   -   1\n" +
+		"  o:    1   -   1\n" +
+		"  r:    2   -   2\n" +
+		"  Σ:    4   1   5\n" +
+		"\n" +
+		"Two letter list:\n" +
+		"  ad-1\n" +
+		"  do-1\n" +
+		"  od-1\n" +
+		"  ro-2\n"
+	if view != expected {
+		t.Errorf("hintsView =\n%s\nexpected\n%s", view, expected)
+	}
+}
@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+// historyLimit is the number of searches kept in the history.
+const historyLimit = 100
+
+// historyDelay is how long results must stay on screen before their search
+// is added to the history, so that the searches made while typing are not.
+const historyDelay = 2 * time.Second
+
+// savedQuery is a search remembered in the history or as a favourite, with
+// the number of words it found and when.
+type savedQuery struct {
+	Locale        string    `json:"locale"`
+	SingleLetter  string    `json:"singleLetter"`
+	SixCharString string    `json:"sixCharString,omitempty"`
+	Length        int       `json:"length,omitempty"`
+	Results       int       `json:"results"`
+	Time          time.Time `json:"time"`
+}
+
+// newSavedQuery remembers q, which found results words, as of now.
+func newSavedQuery(q woordsoek.Query, results int) savedQuery {
+	return savedQuery{
+		Locale:        q.Locale,
+		SingleLetter:  q.SingleLetter,
+		SixCharString: q.SixCharString,
+		Length:        q.Length,
+		Results:       results,
+		Time:          time.Now(),
+	}
+}
+
+func (s savedQuery) query() woordsoek.Query {
+	return woordsoek.Query{Locale: s.Locale, SingleLetter: s.SingleLetter, SixCharString: s.SixCharString, Length: s.Length}
+}
+
+// is reports whether s is a search for q.
+func (s savedQuery) is(q woordsoek.Query) bool {
+	return s.query() == q
+}
+
+// String describes the search, such as "o + aedor, 5 letters".
+func (s savedQuery) String() string {
+	text := s.SingleLetter
+	if s.SixCharString != "" {
+		text += " + " + s.SixCharString
+	}
+	if s.Length > 0 {
+		text += ", " + strconv.Itoa(s.Length) + " letters"
+	}
+	return text
+}
+
+// favouriteWord is a word starred in the results of Query.
+type favouriteWord struct {
+	Word  string     `json:"word"`
+	Query savedQuery `json:"query"`
+}
+
+// history holds the searches made in the TUI, oldest first, and the
+// searches and words starred as favourites.
+type history struct {
+	Searches []savedQuery    `json:"searches"`
+	Queries  []savedQuery    `json:"favouriteQueries,omitempty"`
+	Words    []favouriteWord `json:"favouriteWords,omitempty"`
+}
+
+// loadHistory reads the history at path. A missing file holds no history
+// yet.
+func loadHistory(path string) (history, error) {
+	var h history
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return h, err
+	}
+	err = json.Unmarshal(data, &h)
+	return h, err
+}
+
+// save writes h to path.
+func (h history) save(path string) error {
+	return writeJSON(path, h)
+}
+
+// add records a search as the latest, dropping an earlier record of the same
+// search and the oldest ones beyond historyLimit.
+func (h *history) add(s savedQuery) {
+	h.Searches = slices.DeleteFunc(h.Searches, func(old savedQuery) bool { return old.is(s.query()) })
+	h.Searches = append(h.Searches, s)
+	if extra := len(h.Searches) - historyLimit; extra > 0 {
+		h.Searches = slices.Delete(h.Searches, 0, extra)
+	}
+}
+
+// starredQuery reports whether q is a favourite.
+func (h history) starredQuery(q woordsoek.Query) bool {
+	return slices.ContainsFunc(h.Queries, func(s savedQuery) bool { return s.is(q) })
+}
+
+// toggleQuery stars s, or unstars it when it is already a favourite. It
+// reports whether s is starred now.
+func (h *history) toggleQuery(s savedQuery) bool {
+	if h.starredQuery(s.query()) {
+		h.Queries = slices.DeleteFunc(h.Queries, func(old savedQuery) bool { return old.is(s.query()) })
+		return false
+	}
+	h.Queries = append(h.Queries, s)
+	return true
+}
+
+// starredWords returns the words starred in locale.
+func (h history) starredWords(locale string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range h.Words {
+		if w.Query.Locale == locale {
+			words[w.Word] = true
+		}
+	}
+	return words
+}
+
+// toggleWord stars word, found by s, or unstars it when it is already a
+// favourite in the same locale. It reports whether word is starred now.
+func (h *history) toggleWord(word string, s savedQuery) bool {
+	same := func(w favouriteWord) bool { return w.Word == word && w.Query.Locale == s.Locale }
+	if slices.ContainsFunc(h.Words, same) {
+		h.Words = slices.DeleteFunc(h.Words, same)
+		return false
+	}
+	h.Words = append(h.Words, favouriteWord{Word: word, Query: s})
+	return true
+}
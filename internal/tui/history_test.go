@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+func TestHistory(t *testing.T) {
+	var h history
+	for i := 0; i < historyLimit+5; i++ {
+		h.add(savedQuery{Locale: "xx", SingleLetter: "o", Length: i})
+	}
+	if len(h.Searches) != historyLimit || h.Searches[0].Length != 5 {
+		t.Errorf("History holds %d searches from length %d; expected the latest %d", len(h.Searches), h.Searches[0].Length, historyLimit)
+	}
+
+	// Searching again moves a search to the end
+	h.add(savedQuery{Locale: "xx", SingleLetter: "o", Length: 5, Results: 3})
+	if last := h.Searches[len(h.Searches)-1]; len(h.Searches) != historyLimit || last.Length != 5 || last.Results != 3 {
+		t.Errorf("Searching again left %d searches ending in %+v", len(h.Searches), last)
+	}
+
+	q := savedQuery{Locale: "xx", SingleLetter: "o", SixCharString: "aedr"}
+	if !h.toggleQuery(q) || !h.starredQuery(q.query()) {
+		t.Error("toggleQuery did not star a search")
+	}
+	if !h.toggleWord("door", q) || !h.toggleWord("door", savedQuery{Locale: "yy"}) {
+		t.Error("toggleWord did not star a word")
+	}
+	if words := h.starredWords("xx"); !reflect.DeepEqual(words, map[string]bool{"door": true}) {
+		t.Errorf("starredWords(xx) = %v; expected door", words)
+	}
+
+	path := filepath.Join(t.TempDir(), "woordsoek", "history.json")
+	if err := h.save(path); err != nil {
+		t.Fatalf("save returned an error: %v", err)
+	}
+	loaded, err := loadHistory(path)
+	if err != nil || len(loaded.Searches) != historyLimit || len(loaded.Queries) != 1 || len(loaded.Words) != 2 {
+		t.Errorf("loadHistory after save = %d searches, %d queries and %d words, %v", len(loaded.Searches), len(loaded.Queries), len(loaded.Words), err)
+	}
+
+	if h.toggleQuery(q) || h.starredQuery(q.query()) {
+		t.Error("toggleQuery did not unstar a search")
+	}
+	if h.toggleWord("door", q) || h.starredWords("xx")["door"] {
+		t.Error("toggleWord did not unstar a word")
+	}
+}
+
+func TestBrowseHistory(t *testing.T) {
+	m := newTestModel(t)
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	fields := func() string {
+		return m.inputs[singleLetterField].Value() + "|" + m.inputs[sixCharStringField].Value() + "|" + m.inputs[lengthField].Value() + "|" + m.locale
+	}
+
+	// Searches are recorded once their results have stayed on screen
+	for i, letters := range []string{"ad", "er"} {
+		m = m.recall(savedQuery{Locale: "xx", SingleLetter: "o", SixCharString: letters, Length: 4 + i})
+		update(typedMsg{edit: m.edits})
+		update(resultsMsg{seq: m.seq, page: woordsoek.Page{Words: []string{"word"}, Total: 1}})
+		update(recordMsg{seq: m.seq})
+	}
+	m.history.Searches[0].Locale = "yy"
+	if len(m.history.Searches) != 2 {
+		t.Fatalf("History holds %d searches; expected 2", len(m.history.Searches))
+	}
+	saved, err := loadHistory(m.historyPath)
+	if err != nil || len(saved.Searches) != 2 {
+		t.Errorf("The saved history holds %d searches, %v; expected 2", len(saved.Searches), err)
+	}
+
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	draft := fields()
+
+	expected := []struct {
+		key    tea.KeyType
+		fields string
+	}{
+		{tea.KeyUp, "o|er|5|xx"},
+		{tea.KeyUp, "o|ad|4|yy"},
+		{tea.KeyUp, "o|ad|4|yy"},
+		{tea.KeyDown, "o|er|5|xx"},
+		{tea.KeyDown, draft},
+		{tea.KeyDown, draft},
+	}
+	for i, test := range expected {
+		update(tea.KeyMsg{Type: test.key})
+		if fields() != test.fields {
+			t.Errorf("After key %d the fields are %q; expected %q", i+1, fields(), test.fields)
+		}
+	}
+	if draft != "x|er|5|xx" {
+		t.Errorf("The fields were %q before browsing; expected the typed x", draft)
+	}
+}
+
+func TestFavourites(t *testing.T) {
+	m := newTestModel(t)
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+
+	m = m.recall(savedQuery{Locale: "xx", SingleLetter: "o", SixCharString: "dr"})
+	update(typedMsg{edit: m.edits})
+	update(resultsMsg{seq: m.seq, page: woordsoek.Page{Words: []string{"door", "odor"}, Total: 2}})
+
+	update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	update(tea.KeyMsg{Type: tea.KeyTab})
+	update(tea.KeyMsg{Type: tea.KeyTab})
+	update(tea.KeyMsg{Type: tea.KeyTab})
+	update(tea.KeyMsg{Type: tea.KeyDown})
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'*'}})
+	if !m.history.starredQuery(m.query) || !m.starred["odor"] {
+		t.Fatalf("Starred %+v and words %v; expected the search and odor", m.history.Queries, m.starred)
+	}
+
+	// Start again elsewhere, then pick the starred search
+	m = m.recall(savedQuery{Locale: "yy", SingleLetter: "e"})
+	update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if m.currentState != browsingFavourites || len(m.favourites.Items()) != 2 {
+		t.Fatalf("ctrl+o showed state %d with %d favourites; expected both favourites", m.currentState, len(m.favourites.Items()))
+	}
+	update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.currentState != editing || m.query.Locale != "xx" || m.query.SixCharString != "dr" || !m.loading {
+		t.Errorf("Picking the starred search gave state %d and query %+v; expected a search for it", m.currentState, m.query)
+	}
+
+	// Unstarring the word leaves the search
+	update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	update(tea.KeyMsg{Type: tea.KeyDown})
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if len(m.history.Words) != 0 || len(m.history.Queries) != 1 || len(m.favourites.Items()) != 1 {
+		t.Errorf("After x %d words and %d searches are starred; expected only the search", len(m.history.Words), len(m.history.Queries))
+	}
+}
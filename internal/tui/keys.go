@@ -0,0 +1,248 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// keyMap holds the keys of every action in the TUI. The defaults can be
+// replaced per action with tui.keys in the configuration.
+type keyMap struct {
+	Back       key.Binding // cancels a search, clears a filter, leaves a screen or quits
+	Quit       key.Binding
+	Confirm    key.Binding
+	NextField  key.Binding
+	PrevField  key.Binding
+	Up         key.Binding // steps back through the history, or leaves the results at the top
+	Down       key.Binding
+	Filter     key.Binding
+	PageUp     key.Binding
+	PageDown   key.Binding
+	Select     key.Binding
+	Copy       key.Binding
+	StarWord   key.Binding
+	StarSearch key.Binding
+	Unstar     key.Binding
+	Export     key.Binding
+	NextFormat key.Binding
+	Hints      key.Binding
+	Clear      key.Binding
+	Dictionary key.Binding
+	Favourites key.Binding
+	Theme      key.Binding
+	Help       key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Back:       key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "quit")),
+		Quit:       key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit")),
+		Confirm:    key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "next field")),
+		NextField:  key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next field")),
+		PrevField:  key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous field")),
+		Up:         key.NewBinding(key.WithKeys("up"), key.WithHelp("↑", "older search")),
+		Down:       key.NewBinding(key.WithKeys("down"), key.WithHelp("↓", "newer search")),
+		Filter:     key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+		PageUp:     key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "previous page")),
+		PageDown:   key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "next page")),
+		Select:     key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select")),
+		Copy:       key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy")),
+		StarWord:   key.NewBinding(key.WithKeys("*"), key.WithHelp("*", "star word")),
+		StarSearch: key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "star search")),
+		Unstar:     key.NewBinding(key.WithKeys("x", "delete"), key.WithHelp("x", "unstar")),
+		Export:     key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "export")),
+		NextFormat: key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "format (txt, csv, json, md)")),
+		Hints:      key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "hints")),
+		Clear:      key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "clear")),
+		Dictionary: key.NewBinding(key.WithKeys("ctrl+l"), key.WithHelp("ctrl+l", "dictionary")),
+		Favourites: key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "favourites")),
+		Theme:      key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "theme")),
+		Help:       key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+	}
+}
+
+// actions names the bindings of m the way tui.keys in the configuration
+// does.
+func (m *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"back":        &m.Back,
+		"quit":        &m.Quit,
+		"confirm":     &m.Confirm,
+		"next_field":  &m.NextField,
+		"prev_field":  &m.PrevField,
+		"up":          &m.Up,
+		"down":        &m.Down,
+		"filter":      &m.Filter,
+		"page_up":     &m.PageUp,
+		"page_down":   &m.PageDown,
+		"select":      &m.Select,
+		"copy":        &m.Copy,
+		"star_word":   &m.StarWord,
+		"star_search": &m.StarSearch,
+		"unstar":      &m.Unstar,
+		"export":      &m.Export,
+		"next_format": &m.NextFormat,
+		"hints":       &m.Hints,
+		"clear":       &m.Clear,
+		"dictionary":  &m.Dictionary,
+		"favourites":  &m.Favourites,
+		"theme":       &m.Theme,
+		"help":        &m.Help,
+	}
+}
+
+// newKeyMap returns the default keys with those in overrides, by action
+// name, put in their place. The help shows the first key of each.
+func newKeyMap(overrides map[string][]string) (keyMap, error) {
+	m := defaultKeyMap()
+	actions := m.actions()
+	for name, keys := range overrides {
+		b, ok := actions[name]
+		if !ok {
+			return defaultKeyMap(), fmt.Errorf("tui.keys: unknown action %q, use one of %s", name, strings.Join(actionNames(), ", "))
+		}
+		if len(keys) == 0 {
+			return defaultKeyMap(), fmt.Errorf("tui.keys.%s needs at least one key", name)
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(keyName(keys[0]), b.Help().Desc)
+	}
+
+	for _, s := range screens {
+		bound := make(map[string]string)
+		for _, name := range s.actions {
+			for _, k := range actions[name].Keys() {
+				if s.typing && typesText(k) {
+					return defaultKeyMap(), fmt.Errorf("tui.keys.%s: %s would be typed into the %s, use a key with a modifier such as ctrl+%s", name, k, s.name, k)
+				}
+				if other, ok := bound[k]; ok {
+					return defaultKeyMap(), fmt.Errorf("tui.keys: %s and %s are both bound to %s on the %s", other, name, k, s.name)
+				}
+				bound[k] = name
+			}
+		}
+	}
+	return m, nil
+}
+
+// screens lists the actions handled on each screen of the TUI, whose keys
+// must differ. Actions on different screens may share keys, as next_field
+// and next_format do. The keys of a screen where text is typed may not be
+// ones that type it.
+var screens = []struct {
+	name    string
+	typing  bool
+	actions []string
+}{
+	{"search fields", true, []string{"quit", "back", "help", "theme", "dictionary", "favourites", "star_search", "export", "hints", "clear",
+		"next_field", "prev_field", "filter", "page_up", "page_down", "confirm", "up", "down"}},
+	{"results", false, []string{"quit", "back", "help", "theme", "dictionary", "favourites", "star_search", "export", "hints", "clear",
+		"next_field", "prev_field", "filter", "page_up", "page_down", "select", "copy", "star_word", "up"}},
+	{"export prompt", false, []string{"quit", "back", "next_format", "confirm"}},
+	{"dictionary picker", false, []string{"quit", "back", "confirm"}},
+	{"favourites", false, []string{"quit", "back", "confirm", "unstar"}},
+}
+
+// typesText reports whether k is a single letter, digit or mark, which the
+// search fields take as text.
+func typesText(k string) bool {
+	r, size := utf8.DecodeRuneInString(k)
+	if size != len(k) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// CheckKeys reports whether every action in keys, as configured under
+// tui.keys, can be bound without two actions on a screen sharing a key.
+func CheckKeys(keys map[string][]string) error {
+	_, err := newKeyMap(keys)
+	return err
+}
+
+// actionNames lists the actions keys can be bound to, sorted.
+func actionNames() []string {
+	var m keyMap
+	var names []string
+	for name := range m.actions() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// keyName is how key k is shown in the help.
+func keyName(k string) string {
+	switch k {
+	case " ":
+		return "space"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	}
+	return k
+}
+
+// filterKeys makes the back key clear the filter of l, or stop one being
+// typed, as it does everywhere else.
+func (k keyMap) filterKeys(l list.Model) list.Model {
+	l.KeyMap.ClearFilter = k.Back
+	l.KeyMap.CancelWhileFiltering = k.Back
+	return l
+}
+
+// withHelp returns b described as desc.
+func withHelp(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// shortHelp lists the keys that apply to what is on screen.
+func (m Model) shortHelp() []key.Binding {
+	k := m.keys
+	var bindings []key.Binding
+	switch {
+	case m.currentState == exporting:
+		return []key.Binding{k.NextFormat, withHelp(k.Confirm, "export"), withHelp(k.Back, "cancel")}
+	case m.focusedInput == resultsPane:
+		bindings = append(bindings, k.Filter, k.Select, k.Copy, k.StarWord)
+	default:
+		bindings = append(bindings, k.NextField, withHelp(k.Up, "history"))
+	}
+	if m.resultsShown() {
+		bindings = append(bindings, k.PageDown, k.Hints, k.Export)
+	} else if m.showHints {
+		bindings = append(bindings, withHelp(k.Hints, "words"))
+	}
+	if m.query.SingleLetter != "" {
+		bindings = append(bindings, k.StarSearch)
+	}
+	bindings = append(bindings, k.Favourites, k.Dictionary, k.Clear)
+	switch {
+	case m.loading:
+		bindings = append(bindings, withHelp(k.Back, "cancel search"))
+	case m.list.IsFiltered():
+		bindings = append(bindings, withHelp(k.Back, "clear filter"))
+	default:
+		bindings = append(bindings, k.Back)
+	}
+	return append(bindings, k.Help)
+}
+
+// fullHelp lists every key, grouped into columns: the fields, the results,
+// searches and the rest.
+func (k keyMap) fullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.NextField, k.PrevField, k.Confirm, withHelp(k.Up, "older search"), k.Down, k.Clear},
+		{k.Filter, k.PageUp, k.PageDown, k.Select, k.Copy, k.StarWord, k.Hints},
+		{k.StarSearch, k.Favourites, withHelp(k.Unstar, "unstar favourite"), k.Export, k.NextFormat, k.Dictionary},
+		{withHelp(k.Back, "cancel, go back or quit"), k.Quit, k.Theme, withHelp(k.Help, "close help")},
+	}
+}
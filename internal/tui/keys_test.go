@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+func TestKeyOverrides(t *testing.T) {
+	if err := CheckKeys(map[string][]string{"export": {"ctrl+e"}}); err != nil {
+		t.Errorf("CheckKeys rejected a known action: %v", err)
+	}
+	if err := CheckKeys(map[string][]string{"exprot": {"ctrl+e"}}); err == nil || !strings.Contains(err.Error(), "export") {
+		t.Errorf("CheckKeys of an unknown action = %v; expected an error listing the actions", err)
+	}
+	if err := CheckKeys(map[string][]string{"export": {}}); err == nil {
+		t.Error("CheckKeys accepted an action without keys")
+	}
+	if err := CheckKeys(map[string][]string{"back": {"enter"}}); err == nil || !strings.Contains(err.Error(), "confirm") {
+		t.Errorf("CheckKeys of back on enter = %v; expected it to clash with confirm", err)
+	}
+	// Actions that are never handled on the same screen may share a key
+	if err := CheckKeys(map[string][]string{"unstar": {"y"}}); err != nil {
+		t.Errorf("CheckKeys rejected unstar on the copy key: %v", err)
+	}
+	// Letters and digits would be typed into the search fields instead
+	for _, k := range []string{"h", "é", "7"} {
+		if err := CheckKeys(map[string][]string{"hints": {"f2", k}}); err == nil || !strings.Contains(err.Error(), "search fields") {
+			t.Errorf("CheckKeys of hints on %s = %v; expected it to be rejected", k, err)
+		}
+	}
+	if err := CheckKeys(map[string][]string{"copy": {"c"}, "help": {"!"}}); err != nil {
+		t.Errorf("CheckKeys rejected keys that are not typed into the search fields: %v", err)
+	}
+
+	cfg := loadTestConfig(t, "--locale", "xx")
+	cfg.TUI.Keys = map[string][]string{"export": {"ctrl+e"}, "hints": {"f2"}}
+	m := InitializeModel(cfg, Flags{})
+
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	update(typedMsg{edit: m.edits})
+	words := []string{"door", "odor"}
+	update(resultsMsg{seq: m.seq, page: woordsoek.Page{Words: words, Total: 2}, hints: woordsoek.NewHints(words, m.query)})
+
+	if view := m.View(); !strings.Contains(view, "ctrl+e export") {
+		t.Errorf("The help does not show the rebound export key:\n%s", view)
+	}
+	update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.currentState == exporting {
+		t.Error("The default export key still exports after being rebound")
+	}
+	update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if m.currentState != exporting {
+		t.Error("The rebound export key does not export")
+	}
+	update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	update(tea.KeyMsg{Type: tea.KeyF2})
+	if !m.showHints {
+		t.Error("The rebound hints key does not show the hints")
+	}
+	if view := m.View(); !strings.Contains(view, "f2 words") {
+		t.Errorf("The help does not show the rebound hints key:\n%s", view)
+	}
+}
+
+func TestMessagesNameReboundKeys(t *testing.T) {
+	cfg := loadTestConfig(t, "--locale", "xx")
+	cfg.TUI.Keys = map[string][]string{"dictionary": {"f3"}, "confirm": {"f4"}}
+	m := InitializeModel(cfg, Flags{})
+
+	if msg := m.friendlyError(errors.ErrDictionaryNotFound); !strings.Contains(msg, "'f3'") {
+		t.Errorf("friendlyError(%v) = %q; expected it to name the dictionary key", errors.ErrDictionaryNotFound, msg)
+	}
+
+	m.currentState = exporting
+	m, _ = m.exported(exportedMsg{path: "words.txt", err: os.ErrExist})
+	if !strings.Contains(m.exportError, "Press f4 again") {
+		t.Errorf("The export error is %q; expected it to name the confirm key", m.exportError)
+	}
+}
+
+func TestBackClearsFilter(t *testing.T) {
+	cfg := loadTestConfig(t, "--locale", "xx")
+	cfg.TUI.Keys = map[string][]string{"back": {"ctrl+b"}}
+	m := InitializeModel(cfg, Flags{})
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	update(typedMsg{edit: m.edits})
+	words := []string{"door", "odor"}
+	update(resultsMsg{seq: m.seq, page: woordsoek.Page{Words: words, Total: 2}, hints: woordsoek.NewHints(words, m.query)})
+
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.list.IsFiltered() {
+		t.Fatal("The results are not filtered")
+	}
+	update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !m.list.IsFiltered() {
+		t.Error("esc cleared the filter after back was rebound")
+	}
+	update(tea.KeyMsg{Type: tea.KeyCtrlB})
+	if m.list.IsFiltered() {
+		t.Error("The rebound back key did not clear the filter")
+	}
+}
+
+func TestHelpOverlay(t *testing.T) {
+	m := newTestModel(t)
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+
+	if view := m.View(); strings.Contains(view, "older search") || !strings.Contains(view, "? help") {
+		t.Fatalf("The short help is missing or the full help is shown:\n%s", view)
+	}
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	if view := m.View(); !strings.Contains(view, "older search") || !strings.Contains(view, "ctrl+g theme") {
+		t.Errorf("? does not show every key:\n%s", view)
+	}
+	if m.inputs[singleLetterField].Value() != "" {
+		t.Errorf("? was typed into the field: %q", m.inputs[singleLetterField].Value())
+	}
+
+	// esc closes the help rather than quitting
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if m.showHelp || cmd != nil {
+		t.Errorf("esc left showHelp = %v with a command %v; expected the help closed", m.showHelp, cmd)
+	}
+}
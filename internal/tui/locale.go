@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+// localeItem is a dictionary in the locale picker.
+type localeItem struct {
+	woordsoek.LocaleInfo
+}
+
+func (l localeItem) FilterValue() string {
+	return l.Name + " " + l.Locale
+}
+
+func (l localeItem) Title() string {
+	return l.Name
+}
+
+func (l localeItem) Description() string {
+	return l.Locale + " · " + groupThousands(l.Words) + " words"
+}
+
+// localesMsg carries the dictionaries found by loadLocales.
+type localesMsg struct {
+	items []list.Item
+	err   error
+}
+
+// newPicker creates the list the locale is picked from.
+func newPicker() list.Model {
+	picker := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	picker.Title = "Choose a dictionary"
+	picker.SetStatusBarItemName("dictionary", "dictionaries")
+	// Esc goes back instead
+	picker.DisableQuitKeybindings()
+	return picker
+}
+
+// loadLocales lists the dictionaries with their word counts. Counting means
+// reading every file, so it runs outside the update loop.
+func loadLocales(engine *woordsoek.Engine) tea.Cmd {
+	return func() tea.Msg {
+		infos, err := engine.LocaleInfos()
+		if err != nil {
+			return localesMsg{err: err}
+		}
+		items := make([]list.Item, len(infos))
+		for i, info := range infos {
+			if !info.Loaded {
+				if info.Words, err = engine.WordCount(info.Locale); err != nil {
+					slog.Warn("Failed to count the words in a dictionary", "locale", info.Locale, "error", err)
+				}
+			}
+			items[i] = localeItem{info}
+		}
+		return localesMsg{items: items}
+	}
+}
+
+// openPicker shows the locale picker until a locale is picked or the picker
+// is left with esc.
+func (m Model) openPicker() (Model, tea.Cmd) {
+	m.currentState = pickingLocale
+	m.picker.ResetFilter()
+	if len(m.picker.Items()) > 0 {
+		m.selectLocale()
+		return m, nil
+	}
+	return m, tea.Batch(m.picker.StartSpinner(), loadLocales(m.engine))
+}
+
+// selectLocale highlights the current locale in the picker.
+func (m *Model) selectLocale() {
+	for i, item := range m.picker.Items() {
+		if item.(localeItem).Locale == m.locale {
+			m.picker.Select(i)
+			return
+		}
+	}
+}
+
+func (m Model) updatePicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case localesMsg:
+		m.picker.StopSpinner()
+		if msg.err != nil {
+			slog.Error("Failed to list dictionaries", "error", msg.err)
+			m.errorMessage = "The dictionaries in '" + m.cfg.DictionaryDir + "' could not be listed."
+			m.currentState = editing
+			return m, nil
+		}
+		cmd := m.picker.SetItems(msg.items)
+		m.selectLocale()
+		return m, cmd
+
+	case tea.KeyMsg:
+		filtering := m.picker.FilterState() == list.Filtering
+		switch {
+		case key.Matches(msg, m.keys.Confirm):
+			if item, ok := m.picker.SelectedItem().(localeItem); ok {
+				return m.chooseLocale(item.Locale)
+			}
+		case key.Matches(msg, m.keys.Back) && m.picker.FilterState() == list.Unfiltered:
+			m.currentState = editing
+			return m, nil
+		case msg.Type == tea.KeyRunes && !filtering:
+			// Typing filters straight away, without pressing / first
+			m.picker, _ = m.picker.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+		}
+	}
+
+	var cmd tea.Cmd
+	m.picker, cmd = m.picker.Update(msg)
+	return m, cmd
+}
+
+// chooseLocale switches to locale and remembers it for the next session.
+// The fields are searched again in the new dictionary.
+func (m Model) chooseLocale(locale string) (tea.Model, tea.Cmd) {
+	m.locale = locale
+	m.currentState = editing
+	if m.prefsPath != "" {
+		m.prefs.Locale = locale
+		if err := m.prefs.save(m.prefsPath); err != nil {
+			slog.Warn("Failed to remember the locale", "path", m.prefsPath, "error", err)
+		}
+	}
+	return m.searchWords()
+}
+
+// groupThousands formats n with spaces between groups of three digits, as
+// in 123 456.
+func groupThousands(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + " " + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGroupThousands(t *testing.T) {
+	tests := []struct {
+		n        int
+		expected string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1 000"},
+		{173706, "173 706"},
+		{1234567, "1 234 567"},
+	}
+	for _, test := range tests {
+		if s := groupThousands(test.n); s != test.expected {
+			t.Errorf("groupThousands(%d) = %q; expected %q", test.n, s, test.expected)
+		}
+	}
+}
+
+func TestPickLocale(t *testing.T) {
+	cfg := loadTestConfig(t)
+	for _, locale := range []string{"af-za", "nl"} {
+		if err := os.WriteFile(filepath.Join(cfg.DictionaryDir, locale+".txt"), []byte("deur\nrooi\n"), 0644); err != nil {
+			t.Fatalf("Failed to write the %s dictionary: %v", locale, err)
+		}
+	}
+
+	// The first run asks for a dictionary
+	m := InitializeModel(cfg, Flags{})
+	if m.currentState != pickingLocale {
+		t.Fatalf("The first run is in state %d; expected the locale picker", m.currentState)
+	}
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	update(loadLocales(m.engine)())
+	if n := len(m.picker.Items()); n != 2 {
+		t.Fatalf("The picker lists %d dictionaries; expected 2", n)
+	}
+	update(tea.KeyMsg{Type: tea.KeyDown})
+	update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.currentState != editing || m.locale != "nl" {
+		t.Fatalf("After picking the second dictionary state = %d with locale %q; expected to search nl", m.currentState, m.locale)
+	}
+
+	// The locale picked is used by the next session
+	if m := InitializeModel(cfg, Flags{}); m.currentState != editing || m.locale != "nl" {
+		t.Errorf("The next session is in state %d with locale %q; expected to search nl", m.currentState, m.locale)
+	}
+
+	// Leaving the picker keeps the locale
+	var cmd tea.Cmd
+	m, cmd = m.openPicker()
+	if cmd != nil {
+		t.Error("Opening the picker again lists the dictionaries again")
+	}
+	update(tea.KeyMsg{Type: tea.KeyUp})
+	update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.currentState != editing || m.locale != "nl" {
+		t.Errorf("After leaving the picker state = %d with locale %q; expected to search nl", m.currentState, m.locale)
+	}
+}
+
+func TestConfiguredLocale(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "nl"},
+		{[]string{"--locale", "en"}, "en"},
+		// The default locale, when asked for, is not replaced by the one
+		// picked earlier
+		{[]string{"--locale", "af-za"}, "af-za"},
+	}
+	for _, test := range tests {
+		cfg := loadTestConfig(t, test.args...)
+		picked := prefs{Locale: "nl"}
+		path, err := defaultPrefsPath()
+		if err != nil {
+			t.Fatalf("defaultPrefsPath returned an error: %v", err)
+		}
+		if err := picked.save(path); err != nil {
+			t.Fatalf("Failed to save %+v: %v", picked, err)
+		}
+
+		if m := InitializeModel(cfg, Flags{}); m.locale != test.expected || m.currentState != editing {
+			t.Errorf("InitializeModel with %q and nl picked searches %q in state %d; expected %q", test.args, m.locale, m.currentState, test.expected)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// prefs are the choices made in the TUI that are remembered between
+// sessions.
+type prefs struct {
+	Locale string `json:"locale,omitempty"`
+	Theme  string `json:"theme,omitempty"`
+}
+
+// defaultPrefsPath returns where prefs are kept: woordsoek/tui.json in the
+// user's configuration directory.
+func defaultPrefsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "woordsoek", "tui.json"), nil
+}
+
+// loadPrefs reads the prefs at path. A missing file holds no prefs yet.
+func loadPrefs(path string) (prefs, error) {
+	var p prefs
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, nil
+		}
+		return p, err
+	}
+	err = json.Unmarshal(data, &p)
+	return p, err
+}
+
+// save writes p to path.
+func (p prefs) save(path string) error {
+	return writeJSON(path, p)
+}
+
+// writeJSON writes v to path as indented JSON, replacing the previous
+// contents in one step so that a crash cannot leave a half-written file.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
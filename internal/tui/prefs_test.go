@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrefs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "woordsoek", "tui.json")
+
+	p, err := loadPrefs(path)
+	if err != nil || p != (prefs{}) {
+		t.Fatalf("loadPrefs of a missing file = %+v, %v; expected no prefs", p, err)
+	}
+
+	if err := (prefs{Locale: "en"}).save(path); err != nil {
+		t.Fatalf("save returned an error: %v", err)
+	}
+	if p, err = loadPrefs(path); err != nil || p.Locale != "en" {
+		t.Errorf("loadPrefs after save = %+v, %v; expected locale en", p, err)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to corrupt prefs: %v", err)
+	}
+	if _, err := loadPrefs(path); err == nil {
+		t.Error("loadPrefs accepted a corrupt file")
+	}
+}
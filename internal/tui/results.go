@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// lengthLabelWidth is the width of the column naming the length of the words
+// next to it.
+const lengthLabelWidth = 16
+
+// wordDelegate draws a result on one line: a cursor, whether the word is
+// selected or a favourite and, beside the first word of each length, the
+// length and the number of words of that length over all results.
+type wordDelegate struct {
+	selected map[string]bool
+	starred  map[string]bool
+	totals   map[int]int
+	theme    theme
+}
+
+func (d wordDelegate) Height() int                         { return 1 }
+func (d wordDelegate) Spacing() int                        { return 0 }
+func (d wordDelegate) Update(tea.Msg, *list.Model) tea.Cmd { return nil }
+
+func (d wordDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	word := string(item.(wordItem))
+
+	cursor, shown := "  ", word
+	if index == m.Index() {
+		cursor, shown = "> ", d.theme.cursor.Render(word)
+	}
+	mark := "  "
+	if d.selected[word] {
+		mark = "✓ "
+	}
+	if d.starred[word] {
+		mark += "★ "
+	} else {
+		mark += "  "
+	}
+
+	// Filtered results are ranked by how well they match, so the lengths
+	// are only labelled where they change, and at the top of each page
+	label := ""
+	n := utf8.RuneCountInString(word)
+	pageTop := m.Paginator.PerPage > 0 && index%m.Paginator.PerPage == 0
+	if visible := m.VisibleItems(); index == 0 || pageTop || utf8.RuneCountInString(string(visible[index-1].(wordItem))) != n {
+		label = strconv.Itoa(n) + " letters"
+		if total, ok := d.totals[n]; ok {
+			label += " (" + strconv.Itoa(total) + ")"
+		}
+	}
+	// Padded before styling, which would count towards the width
+	label = d.theme.faint.Render(fmt.Sprintf("%-*s", lengthLabelWidth, label))
+	fmt.Fprintf(w, "%s%s %s%s", d.theme.cursor.Render(cursor), label, d.theme.mark.Render(mark), shown)
+}
+
+// wordDelegate draws the results in the theme, counting the words of each
+// length in the hints.
+func (m Model) wordDelegate() wordDelegate {
+	totals := make(map[int]int, len(m.hints.Lengths))
+	for i, n := range m.hints.Lengths {
+		totals[n] = m.hints.LengthTotals[i]
+	}
+	return wordDelegate{selected: m.selected, starred: m.starred, totals: totals, theme: m.theme}
+}
+
+// copiedMsg reports the outcome of copying words to the clipboard. When no
+// clipboard tool could be used, osc52 holds the sequence that asks the
+// terminal to set the clipboard instead.
+type copiedMsg struct {
+	words int
+	osc52 string
+	err   error
+}
+
+// osc52SentMsg fires once the sequence osc52 has gone out with a frame.
+type osc52SentMsg struct {
+	osc52 string
+}
+
+// osc52Delay is how long an OSC52 sequence stays in the view, long enough
+// for a frame to be drawn.
+const osc52Delay = 500 * time.Millisecond
+
+// copyToClipboard puts text on the clipboard with a clipboard tool. Over SSH
+// the local clipboard is out of reach, so the OSC52 sequence that asks the
+// terminal to set it is returned instead, as it is when there is no tool to
+// use. The sequence is sent with the next frame by View, as writing it here
+// could tear a frame being drawn.
+var copyToClipboard = func(text string) (string, error) {
+	if os.Getenv("SSH_TTY") == "" && os.Getenv("SSH_CONNECTION") == "" {
+		if err := clipboard.WriteAll(text); err == nil {
+			return "", nil
+		}
+	}
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	return seq.String(), nil
+}
+
+// toCopy returns the selected words in the order shown or, when none are
+// selected, every word that passes the filter.
+func (m Model) toCopy() []string {
+	var all, selected []string
+	for _, item := range m.list.VisibleItems() {
+		word := string(item.(wordItem))
+		all = append(all, word)
+		if m.selected[word] {
+			selected = append(selected, word)
+		}
+	}
+	if len(selected) > 0 {
+		return selected
+	}
+	return all
+}
+
+// copyWords copies words to the clipboard, one per line. Clipboard tools
+// may take a moment, so it runs outside the update loop.
+func copyWords(words []string) tea.Cmd {
+	return func() tea.Msg {
+		seq, err := copyToClipboard(strings.Join(words, "\n") + "\n")
+		return copiedMsg{words: len(words), osc52: seq, err: err}
+	}
+}
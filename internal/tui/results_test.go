@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+func TestSelectAndCopy(t *testing.T) {
+	var copied []string
+	original := copyToClipboard
+	copyToClipboard = func(text string) (string, error) {
+		copied = append(copied, text)
+		return "", nil
+	}
+	t.Cleanup(func() { copyToClipboard = original })
+
+	m := newTestModel(t)
+	update := func(msg tea.Msg) tea.Cmd {
+		t.Helper()
+		next, cmd := m.Update(msg)
+		m = next.(Model)
+		return cmd
+	}
+	press := func(s string) tea.Cmd {
+		t.Helper()
+		switch s {
+		case "tab":
+			return update(tea.KeyMsg{Type: tea.KeyTab})
+		case "down":
+			return update(tea.KeyMsg{Type: tea.KeyDown})
+		case "space":
+			return update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		}
+		return update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+	}
+
+	press("o")
+	update(typedMsg{edit: m.edits})
+	words := []string{"door", "odor", "adore"}
+	update(resultsMsg{seq: m.seq, page: woordsoek.Page{Words: words, Total: 3}, hints: woordsoek.NewHints(words, m.query)})
+
+	// y is a letter until the results have focus
+	press("y")
+	if m.inputs[singleLetterField].Value() != "y" {
+		t.Fatalf("Typing y in a field gave %q; expected y", m.inputs[singleLetterField].Value())
+	}
+	m.inputs[singleLetterField].SetValue("o")
+
+	press("tab")
+	press("tab")
+	press("tab")
+	if m.focusedInput != resultsPane {
+		t.Fatalf("Focus = %d after tabbing past the fields; expected the results", m.focusedInput)
+	}
+
+	// Nothing selected copies every word
+	if cmd := press("y"); cmd != nil {
+		update(cmd())
+	}
+	press("space")
+	press("down")
+	press("down")
+	press("space")
+	if cmd := press("y"); cmd != nil {
+		update(cmd())
+	}
+
+	expected := []string{"door\nodor\nadore\n", "door\nadore\n"}
+	if len(copied) != 2 || copied[0] != expected[0] || copied[1] != expected[1] {
+		t.Errorf("Copied %q; expected %q", copied, expected)
+	}
+
+	// Without a clipboard tool the terminal is asked to set it, with the
+	// next frame
+	copyToClipboard = func(text string) (string, error) { return "\x1b]52;c;ZG9vcgo=\a", nil }
+	var sent tea.Cmd
+	if cmd := press("y"); cmd != nil {
+		sent = update(cmd())
+	}
+	if view := m.View(); !strings.HasPrefix(view, "\x1b]52;c;ZG9vcgo=\a") {
+		t.Errorf("The view after copying over OSC52 starts %q; expected the OSC52 sequence", view[:min(len(view), 20)])
+	}
+	update(osc52SentMsg{osc52: m.osc52})
+	if view := m.View(); strings.Contains(view, "\x1b]52") {
+		t.Error("The OSC52 sequence is still sent after it went out")
+	}
+	if sent == nil {
+		t.Error("Copying over OSC52 does not stop sending the sequence")
+	}
+
+	press("/")
+	if !m.list.SettingFilter() {
+		t.Error("/ did not start filtering the results")
+	}
+}
+
+func TestGroupedView(t *testing.T) {
+	items := []list.Item{wordItem("door"), wordItem("odor"), wordItem("rode"), wordItem("adore")}
+	results := list.New(items, wordDelegate{totals: map[int]int{4: 3, 5: 1}, theme: themes[0]}, 30, 3)
+	results.SetShowTitle(false)
+	results.SetShowStatusBar(false)
+	results.SetShowPagination(false)
+	results.SetShowHelp(false)
+
+	// Each page names the length of its first word, even when it goes on
+	// from the page before
+	pages := [][]string{
+		{"> 4 letters (3)        door", "                       odor"},
+		{"> 4 letters (3)        rode", "  5 letters (1)        adore"},
+	}
+	for i, expected := range pages {
+		var lines []string
+		for _, line := range strings.Split(results.View(), "\n") {
+			if line = strings.TrimRight(line, " "); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("Page %d =\n%s\nexpected\n%s", i+1, strings.Join(lines, "\n"), strings.Join(expected, "\n"))
+		}
+		results, _ = results.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	}
+}
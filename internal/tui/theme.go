@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// theme colours the TUI. Themes are picked with tui.theme in the
+// configuration and cycled with a key at runtime.
+type theme struct {
+	name string
+
+	title       lipgloss.Style
+	prompt      lipgloss.Style // of the focused field
+	text        lipgloss.Style
+	placeholder lipgloss.Style
+	faint       lipgloss.Style // labels, hints and other secondary text
+	cursor      lipgloss.Style // the result under the cursor
+	mark        lipgloss.Style // ✓ and ★
+	err         lipgloss.Style
+	warn        lipgloss.Style
+
+	// The progress bar fills from barStart to barEnd.
+	barStart, barEnd string
+}
+
+// themes in the order they are cycled through.
+var themes = []theme{
+	newTheme("dark", "#7571F9", "#EEEEEE", "#626262", "#FF6F91", "#F2C94C", "#5A56E0", "#EE6FF8"),
+	newTheme("light", "#5A56E0", "#1A1A1A", "#8A8A8A", "#C2185B", "#B7791F", "#5A56E0", "#B03AC0"),
+	{
+		name:        "high-contrast",
+		title:       lipgloss.NewStyle().Bold(true).Reverse(true).Padding(0, 1),
+		prompt:      lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		text:        lipgloss.NewStyle().Foreground(lipgloss.Color("15")),
+		placeholder: lipgloss.NewStyle().Foreground(lipgloss.Color("7")),
+		faint:       lipgloss.NewStyle().Foreground(lipgloss.Color("15")),
+		cursor:      lipgloss.NewStyle().Bold(true).Reverse(true),
+		mark:        lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		err:         lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9")),
+		warn:        lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("14")),
+		barStart:    "#FFFF00",
+		barEnd:      "#FFFF00",
+	},
+}
+
+// newTheme creates a theme with accent for titles, the focused field and the
+// cursor, and mark for selected and starred words.
+func newTheme(name, accent, text, faint, err, warn, barStart, mark string) theme {
+	return theme{
+		name:        name,
+		title:       lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFDF5")).Background(lipgloss.Color(accent)).Padding(0, 1),
+		prompt:      lipgloss.NewStyle().Foreground(lipgloss.Color(accent)),
+		text:        lipgloss.NewStyle().Foreground(lipgloss.Color(text)),
+		placeholder: lipgloss.NewStyle().Foreground(lipgloss.Color(faint)),
+		faint:       lipgloss.NewStyle().Foreground(lipgloss.Color(faint)),
+		cursor:      lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(accent)),
+		mark:        lipgloss.NewStyle().Foreground(lipgloss.Color(mark)),
+		err:         lipgloss.NewStyle().Foreground(lipgloss.Color(err)),
+		warn:        lipgloss.NewStyle().Foreground(lipgloss.Color(warn)),
+		barStart:    barStart,
+		barEnd:      mark,
+	}
+}
+
+// themeNamed returns the theme called name, in any case, or the first theme
+// when there is none by that name.
+func themeNamed(name string) theme {
+	for _, t := range themes {
+		if strings.EqualFold(t.name, name) {
+			return t
+		}
+	}
+	return themes[0]
+}
+
+// nextTheme returns the theme after t.
+func nextTheme(t theme) theme {
+	for i := range themes {
+		if themes[i].name == t.name {
+			return themes[(i+1)%len(themes)]
+		}
+	}
+	return themes[0]
+}
+
+// helpStyles styles the key help in t.
+func (t theme) helpStyles() help.Styles {
+	return help.Styles{
+		Ellipsis:       t.faint,
+		ShortKey:       t.text,
+		ShortDesc:      t.faint,
+		ShortSeparator: t.faint,
+		FullKey:        t.text,
+		FullDesc:       t.faint,
+		FullSeparator:  t.faint,
+	}
+}
+
+// listStyles styles a list in t.
+func (t theme) listStyles() list.Styles {
+	s := list.DefaultStyles()
+	s.Title = t.title
+	s.FilterPrompt = t.prompt
+	s.FilterCursor = t.prompt
+	s.StatusBar = t.faint.Padding(0, 0, 1, 2)
+	s.StatusBarActiveFilter = t.text
+	s.StatusBarFilterCount = t.faint
+	s.NoItems = t.faint
+	return s
+}
+
+// withTheme colours every part of m in t.
+func (m Model) withTheme(t theme) Model {
+	m.theme = t
+	for i := range m.inputs {
+		m.inputs[i].TextStyle = t.text
+		m.inputs[i].PlaceholderStyle = t.placeholder
+		m.inputs[i].Cursor.Style = t.prompt
+		m.inputs[i].PromptStyle = t.faint
+		if i == m.focusedInput {
+			m.inputs[i].PromptStyle = t.prompt
+		}
+	}
+	m.exportInput.PromptStyle = t.prompt
+	m.exportInput.TextStyle = t.text
+	m.exportInput.Cursor.Style = t.prompt
+	m.help.Styles = t.helpStyles()
+	m.spinner.Style = t.prompt
+	m.progressBar = progress.New(progress.WithGradient(t.barStart, t.barEnd), progress.WithWidth(m.progressBar.Width))
+	m.list.Styles = t.listStyles()
+	m.list.SetDelegate(m.wordDelegate())
+	m.picker.Styles = t.listStyles()
+	m.favourites.Styles = t.listStyles()
+
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.Foreground(t.prompt.GetForeground()).BorderForeground(t.prompt.GetForeground())
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.Foreground(t.prompt.GetForeground()).BorderForeground(t.prompt.GetForeground())
+	m.picker.SetDelegate(delegate)
+	m.favourites.SetDelegate(delegate)
+	return m
+}
+
+// cycleTheme switches to the next theme and remembers it for the next
+// session.
+func (m Model) cycleTheme() Model {
+	m = m.withTheme(nextTheme(m.theme))
+	if m.prefsPath != "" {
+		m.prefs.Theme = m.theme.name
+		if err := m.prefs.save(m.prefsPath); err != nil {
+			slog.Warn("Failed to remember the theme", "path", m.prefsPath, "error", err)
+		}
+	}
+	return m
+}
@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jvanrhyn/woordsoek/internal/config"
+)
+
+func TestCycleTheme(t *testing.T) {
+	m := newTestModel(t)
+	if m.theme.name != "dark" {
+		t.Fatalf("Theme = %q; expected the default dark", m.theme.name)
+	}
+
+	for _, expected := range []string{"light", "high-contrast", "dark", "light"} {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+		m = next.(Model)
+		if m.theme.name != expected {
+			t.Fatalf("Theme after ctrl+g = %q; expected %q", m.theme.name, expected)
+		}
+	}
+
+	// The theme picked is used by the next session, unless one is
+	// configured, even the default
+	dir := m.cfg.DictionaryDir
+	for _, test := range []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "light"},
+		{[]string{"--tui-theme", "High-Contrast"}, "high-contrast"},
+		{[]string{"--tui-theme", "dark"}, "dark"},
+	} {
+		cfg, _, err := config.Load("test", append([]string{"--locale", "xx", "--dictionary-dir", dir}, test.args...))
+		if err != nil {
+			t.Fatalf("config.Load(%q) returned an error: %v", test.args, err)
+		}
+		if theme := InitializeModel(cfg, Flags{}).theme.name; theme != test.expected {
+			t.Errorf("Theme in the next session with %q = %q; expected %s", test.args, theme, test.expected)
+		}
+	}
+}
@@ -1,20 +1,28 @@
 package tui
 
 import (
+	"context"
 	"log/slog"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
-	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	configure "github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/errors"
 	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
 )
 
+// Flags prefill the search fields. When SingleLetter is set a search starts
+// straight away, in the default dictionary if none was picked before, and
+// its results get the focus.
 type Flags struct {
 	SingleLetter  string
 	SixCharString string
@@ -24,18 +32,29 @@ type Flags struct {
 type state int
 
 const (
-	inputSingleLetter state = iota
-	inputSixCharString
-	inputLength
-	done
+	editing state = iota
+	pickingLocale
+	exporting
+	browsingFavourites
 )
 
-type wordItem string
+// The search fields, in the order focus moves through them, followed by the
+// results when there are any.
+const (
+	singleLetterField = iota
+	sixCharStringField
+	lengthField
+	resultsPane
+)
 
-func init() {
-	logger := configure.SetupLogging()
-	slog.SetDefault(logger)
-}
+// typingDelay is how long the TUI waits after a change to the fields before
+// searching, so that typing quickly does not start a search per keystroke.
+const typingDelay = 150 * time.Millisecond
+
+// chromeLines is the number of lines on screen that are not results.
+const chromeLines = 8
+
+type wordItem string
 
 func (w wordItem) FilterValue() string {
 	return string(w)
@@ -45,195 +64,720 @@ func (w wordItem) Title() string {
 	return string(w)
 }
 
+// typedMsg fires typingDelay after edit number edit to the fields.
+type typedMsg struct {
+	edit int
+}
+
+// progressMsg reports how far search number seq has got. updates and done
+// are where the next report comes from.
+type progressMsg struct {
+	seq      int
+	progress woordsoek.Progress
+	updates  <-chan woordsoek.Progress
+	done     <-chan struct{}
+}
+
+// resultsMsg carries the words found by search number seq.
+type resultsMsg struct {
+	seq   int
+	page  woordsoek.Page
+	hints woordsoek.Hints
+	err   error
+}
+
 type Model struct {
-	flags        Flags
-	results      []string
-	loading      bool
-	errorMessage string
-	inputs       []textinput.Model
-	focusedInput int
-	currentState state
-	list         list.Model
-	paginator    paginator.Model
-}
-
-func InitializeModel(flags Flags) Model {
+	cfg           *config.Config
+	engine        *woordsoek.Engine
+	flags         Flags
+	locale        string
+	keys          keyMap
+	theme         theme
+	prefs         prefs
+	prefsPath     string // where picked locales are remembered, if anywhere
+	historyPath   string // where the history is kept, if anywhere
+	history       history
+	historyIndex  int      // of the search in the fields, len(history.Searches) for none
+	draftFields   []string // what was typed before browsing the history
+	draftLocale   string
+	query         woordsoek.Query
+	results       []string // every word found, shortest first
+	total         int
+	hints         woordsoek.Hints
+	showHints     bool            // instead of the words
+	showHelp      bool            // every key, instead of the words
+	selected      map[string]bool // words picked from the results
+	starred       map[string]bool // favourite words in the locale searched
+	edits         int             // changes made to the fields so far
+	seq           int             // of the latest search, older results are stale
+	cancelSearch  context.CancelFunc
+	loading       bool
+	progress      woordsoek.Progress // of the search that is loading
+	errorMessage  string
+	inputs        []textinput.Model
+	fieldErrors   []string // beside each field, empty when it is fine
+	letterWarning string
+	focusedInput  int
+	toResults     bool // whether the next results get the focus, as for a search given by Flags
+	currentState  state
+	exportInput   textinput.Model // the file the results are exported to
+	exportError   string
+	osc52         string // sequence setting the clipboard, sent with the next frames
+	overwrite     bool   // whether the export may replace an existing file
+	help          help.Model
+	spinner       spinner.Model
+	progressBar   progress.Model
+	list          list.Model // the results
+	picker        list.Model
+	favourites    list.Model
+}
+
+func InitializeModel(cfg *config.Config, flags Flags) Model {
 	inputs := make([]textinput.Model, 3)
 
 	// SingleLetter input
 	input := textinput.New()
-	input.Placeholder = "Single Letter"
+	input.Prompt = "Single letter: "
+	input.Placeholder = "required"
+	input.SetValue(flags.SingleLetter)
 	input.Focus()
-	inputs[0] = input
+	inputs[singleLetterField] = input
 
 	// SixCharString input
 	input = textinput.New()
-	input.Placeholder = "6-Character String"
-	inputs[1] = input
+	input.Prompt = "Letters:       "
+	input.Placeholder = "letters the words may use"
+	input.CharLimit = maxLetters
+	input.SetValue(flags.SixCharString)
+	inputs[sixCharStringField] = input
 
 	// Length input
 	input = textinput.New()
-	input.Placeholder = "Word Length (0 for any)"
-	inputs[2] = input
+	input.Prompt = "Word length:   "
+	input.Placeholder = "any"
+	input.CharLimit = maxLength
+	if flags.Length > 0 {
+		input.SetValue(strconv.Itoa(flags.Length))
+	}
+	inputs[lengthField] = input
 
-	p := paginator.New()
-	p.Type = paginator.Dots
-	p.PerPage = 10 // Default value, will be updated based on screen height
+	keys, err := newKeyMap(cfg.TUI.Keys)
+	if err != nil {
+		slog.Warn("Using the default keys", "error", err)
+	}
 
-	return Model{
+	m := Model{
+		cfg:          cfg,
+		engine:       woordsoek.NewEngine(cfg.DictionaryDir, woordsoek.WithResultCache(cfg.Cache.Size)),
 		flags:        flags,
-		loading:      false,
+		locale:       cfg.Locale,
+		keys:         keys,
+		cancelSearch: func() {},
 		inputs:       inputs,
-		focusedInput: 0,
-		currentState: inputSingleLetter,
-		list:         list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
-		paginator:    p,
+		focusedInput: singleLetterField,
+		toResults:    flags.SingleLetter != "",
+		currentState: editing,
+		exportInput:  newExportInput(),
+		help:         help.New(),
+		spinner:      spinner.New(spinner.WithSpinner(spinner.Dot)),
+		progressBar:  progress.New(progress.WithWidth(40)),
+		list:         newResultsList(keys),
+		picker:       keys.filterKeys(newPicker()),
+		favourites:   keys.filterKeys(newFavourites()),
 	}
+	m = m.withTheme(themeNamed(cfg.TUI.Theme))
+
+	// A locale picked in an earlier session is used unless another one is
+	// configured. Without either, the picker is shown first.
+	path, err := defaultPrefsPath()
+	if err != nil {
+		slog.Warn("Picked locales will not be remembered", "error", err)
+		return m
+	}
+	m.prefsPath = path
+	m.historyPath = filepath.Join(filepath.Dir(path), "history.json")
+	if m.history, err = loadHistory(m.historyPath); err != nil {
+		slog.Warn("Failed to read the search history", "path", m.historyPath, "error", err)
+	}
+	m.historyIndex = len(m.history.Searches)
+
+	saved, err := loadPrefs(path)
+	if err != nil {
+		slog.Warn("Failed to read remembered choices", "path", path, "error", err)
+	}
+	m.prefs = saved
+	if saved.Theme != "" && !cfg.IsSet("tui.theme") {
+		m = m.withTheme(themeNamed(saved.Theme))
+	}
+	if !cfg.IsSet("locale") {
+		if saved.Locale != "" {
+			m.locale = saved.Locale
+		} else if err == nil && flags.SingleLetter == "" {
+			m.currentState = pickingLocale
+			m.picker.StartSpinner()
+		}
+	}
+	return m
+}
+
+// newResultsList creates the list the results are shown in, sized for a
+// small terminal until the real size is known.
+func newResultsList(keys keyMap) list.Model {
+	results := list.New(nil, wordDelegate{}, 80, 24-chromeLines)
+	results.SetStatusBarItemName("word", "words")
+	// The keys are listed below the results instead
+	results.SetShowHelp(false)
+	results.DisableQuitKeybindings()
+	results.KeyMap.Filter = keys.Filter
+	results.KeyMap.PrevPage = keys.PageUp
+	results.KeyMap.NextPage = keys.PageDown
+	return keys.filterKeys(results)
 }
 
 func (m Model) Init() tea.Cmd {
-	return textinput.Blink
+	cmds := []tea.Cmd{textinput.Blink}
+	if m.currentState == pickingLocale {
+		// The spinner was started by InitializeModel, this keeps it turning
+		cmds = append(cmds, m.picker.StartSpinner(), loadLocales(m.engine))
+	}
+	if m.flags.SingleLetter != "" {
+		edit := m.edits
+		cmds = append(cmds, func() tea.Msg { return typedMsg{edit: edit} })
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && key.Matches(msg, m.keys.Quit) {
+		return m, tea.Quit
+	}
+
+	switch msg := msg.(type) {
+	case localesMsg:
+		return m.updatePicker(msg)
+	case tea.WindowSizeMsg, typedMsg, progressMsg, resultsMsg, recordMsg, copiedMsg, osc52SentMsg, exportedMsg:
+	case spinner.TickMsg:
+		if msg.ID != m.spinner.ID() {
+			return m.updatePicker(msg)
+		}
+	default:
+		switch m.currentState {
+		case pickingLocale:
+			return m.updatePicker(msg)
+		case browsingFavourites:
+			return m.updateFavourites(msg)
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
-			return m, tea.Quit
-		case "tab":
-			if m.currentState == done {
-				// Clear input fields
-				for i := range m.inputs {
-					m.inputs[i].SetValue("") // Clear the input field
-				}
-				m.currentState = inputSingleLetter // Reset to input state
-				m.focusedInput = 0
+		if m.currentState == exporting {
+			return m.updateExport(msg)
+		}
+		// While a filter is typed every key belongs to it
+		if m.list.SettingFilter() {
+			return m.updateList(msg)
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			if m.showHelp {
+				m.showHelp = false
 				return m, nil
 			}
-			return InitializeModel(m.flags), nil
-		case "enter":
-			if m.currentState <= inputLength {
-				if m.currentState == inputSingleLetter {
-					m.flags.SingleLetter = m.inputs[0].Value()
-				} else if m.currentState == inputSixCharString {
-					m.flags.SixCharString = m.inputs[1].Value()
-				} else if m.currentState == inputLength {
-					length, err := strconv.Atoi(m.inputs[2].Value())
-					if err != nil {
-						m.flags.Length = 0
-					} else {
-						m.flags.Length = length
-					}
-					m.currentState = done
-					return m.searchWords(), nil
-				}
-
-				slog.Info("Input values",
-					"SingleLetter", m.flags.SingleLetter,
-					"SixCharString", m.flags.SixCharString,
-					"Length", m.flags.Length,
-				)
-
-				m.currentState++
-				if m.focusedInput < len(m.inputs)-1 {
-					m.focusedInput++
-				}
-				m.inputs[m.focusedInput].Focus()
-				return m, nil
+			if m.loading {
+				// Stop the search rather than the program
+				return m.cancel(), nil
+			}
+			if m.list.IsFiltered() {
+				return m.updateList(msg)
+			}
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = !m.showHelp
+			return m, nil
+		case key.Matches(msg, m.keys.Theme):
+			return m.cycleTheme(), nil
+		case key.Matches(msg, m.keys.Dictionary):
+			return m.openPicker()
+		case key.Matches(msg, m.keys.Favourites):
+			return m.openFavourites()
+		case key.Matches(msg, m.keys.StarSearch):
+			return m.starQuery()
+		case key.Matches(msg, m.keys.Export):
+			if m.resultsShown() {
+				return m.openExport()
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.Hints):
+			m.showHints = !m.showHints
+			if m.showHints && m.focusedInput == resultsPane {
+				m = m.focus(lengthField)
 			}
+			return m, nil
+		case key.Matches(msg, m.keys.Clear):
+			// Start over with empty fields
+			for i := range m.inputs {
+				m.inputs[i].SetValue("")
+			}
+			return m.focus(singleLetterField).typed()
+		case key.Matches(msg, m.keys.NextField):
+			return m.focus(m.nextFocus(1)), nil
+		case key.Matches(msg, m.keys.PrevField):
+			return m.focus(m.nextFocus(-1)), nil
+		case key.Matches(msg, m.keys.Filter):
+			if m.resultsShown() {
+				return m.focus(resultsPane).updateList(msg)
+			}
+		case key.Matches(msg, m.keys.PageUp, m.keys.PageDown):
+			if m.resultsShown() {
+				return m.updateList(msg)
+			}
+			return m, nil
 		}
+
+		if m.focusedInput == resultsPane {
+			return m.updateResults(msg)
+		}
+		switch {
+		case key.Matches(msg, m.keys.Confirm):
+			return m.focus(m.nextFocus(1)), nil
+		case key.Matches(msg, m.keys.Up):
+			return m.browseHistory(-1)
+		case key.Matches(msg, m.keys.Down):
+			return m.browseHistory(1)
+		}
+
 	case tea.WindowSizeMsg:
-		// Update the paginator's PerPage based on the terminal height
-		m.paginator.PerPage = msg.Height / 2 // Adjust this value based on your layout
-	}
+		m.picker.SetSize(msg.Width, msg.Height)
+		m.favourites.SetSize(msg.Width, msg.Height)
+		m.list.SetSize(msg.Width, max(1, msg.Height-chromeLines))
+		m.help.Width = msg.Width
+		m.progressBar.Width = min(40, max(10, msg.Width-30))
+		return m, nil
+
+	case typedMsg:
+		// Only search once typing has paused
+		if msg.edit != m.edits {
+			return m, nil
+		}
+		return m.searchWords()
 
-	// Only update the current input field
-	if m.currentState <= inputLength {
-		m.inputs[m.focusedInput], _ = m.inputs[m.focusedInput].Update(msg)
-	}
+	case progressMsg:
+		if msg.seq != m.seq || !m.loading {
+			return m, nil
+		}
+		m.progress = msg.progress
+		return m, waitForProgress(msg.seq, msg.updates, msg.done)
+
+	case resultsMsg:
+		if msg.seq != m.seq {
+			return m, nil
+		}
+		return m.showResults(msg)
 
-	// Update the paginator model if in done state
-	if m.currentState == done {
+	case recordMsg:
+		return m.record(msg), nil
+
+	case exportedMsg:
+		return m.exported(msg)
+
+	case copiedMsg:
+		if msg.err != nil {
+			slog.Error("Failed to copy words to the clipboard", "error", msg.err)
+			return m, m.list.NewStatusMessage("The words could not be copied.")
+		}
+		status := m.list.NewStatusMessage("Copied " + strconv.Itoa(msg.words) + " words.")
+		if msg.osc52 == "" {
+			return m, status
+		}
+		m.osc52 = msg.osc52
+		return m, tea.Batch(status, tea.Tick(osc52Delay, func(time.Time) tea.Msg {
+			return osc52SentMsg{osc52: msg.osc52}
+		}))
+
+	case osc52SentMsg:
+		if m.osc52 == msg.osc52 {
+			m.osc52 = ""
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if !m.loading {
+			return m, nil
+		}
 		var cmd tea.Cmd
-		m.paginator, cmd = m.paginator.Update(msg)
+		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 	}
 
-	return m, nil
-}
+	// Anything else may be for the results, such as the matches of a
+	// filter, as well as for the focused field
+	var listCmd tea.Cmd
+	if _, ok := msg.(tea.KeyMsg); !ok {
+		m.list, listCmd = m.list.Update(msg)
+	}
+	if m.currentState == exporting {
+		var cmd tea.Cmd
+		m.exportInput, cmd = m.exportInput.Update(msg)
+		return m, tea.Batch(listCmd, cmd)
+	}
+	if m.focusedInput == resultsPane {
+		return m, listCmd
+	}
 
-func (m Model) searchWords() Model {
-	m.loading = true // Set loading to true when starting the search
-	lang := os.Getenv("WBLANG")
-	slog.Info("Language from environment", "lang", lang)
-	if lang == "" {
-		lang = "af-za"
-		slog.Info("Language set to", "lang", lang)
+	// Only update the focused field, searching again if it changed
+	value := m.inputs[m.focusedInput].Value()
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.Type == tea.KeyRunes && len(msg.Runes) > 0 && m.focusedInput == singleLetterField {
+		// A letter typed over the single letter replaces it, while a vowel
+		// sign or accent is added to it
+		if !combining(msg.Runes[0]) {
+			m.inputs[singleLetterField].SetValue("")
+		}
 	}
-	filenamePath := filepath.Join("dictionaries", lang+".txt")
+	var cmd tea.Cmd
+	m.inputs[m.focusedInput], cmd = m.inputs[m.focusedInput].Update(msg)
+	if m.inputs[m.focusedInput].Value() != value {
+		// Typing leaves the history
+		m.historyIndex = len(m.history.Searches)
+		m = m.checkFields()
+		var search tea.Cmd
+		m, search = m.typed()
+		return m, tea.Batch(listCmd, cmd, search)
+	}
+	return m, tea.Batch(listCmd, cmd)
+}
 
-	woordsoek.LoadVowelForms()
-	var err error
-	m.results, err = woordsoek.SearchForMatchingWords(filenamePath, m.flags.SingleLetter, m.flags.SixCharString, m.flags.Length)
-	if err != nil {
-		m.errorMessage = "Error searching for words: " + err.Error()
-		slog.Error("Error searching for words", "error", err)
+// updateResults handles a key pressed while the results have focus.
+func (m Model) updateResults(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		if item, ok := m.list.SelectedItem().(wordItem); ok {
+			word := string(item)
+			if m.selected[word] {
+				delete(m.selected, word)
+			} else {
+				m.selected[word] = true
+			}
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Copy):
+		if words := m.toCopy(); len(words) > 0 {
+			return m, copyWords(words)
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.StarWord):
+		return m.starWord()
+	case key.Matches(msg, m.keys.Up):
+		if m.list.Index() == 0 {
+			return m.focus(lengthField), nil
+		}
 	}
-	m.loading = false
+	return m.updateList(msg)
+}
+
+// updateList passes msg on to the results.
+func (m Model) updateList(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// resultsShown reports whether there are results on screen to move to.
+func (m Model) resultsShown() bool {
+	return m.total > 0 && !m.showHints
+}
 
-	// Populate the list with results
-	var items []list.Item
-	for _, word := range m.results {
-		items = append(items, wordItem(word))
+// nextFocus returns the field step places after the focused one, counting
+// the results when they are shown.
+func (m Model) nextFocus(step int) int {
+	n := len(m.inputs)
+	if m.resultsShown() {
+		n++
 	}
-	m.list = list.New(items, list.NewDefaultDelegate(), 0, len(items))
-	m.paginator.SetTotalPages(len(items))
+	return ((m.focusedInput+step)%n + n) % n
+}
 
+// focus moves the cursor to field i, or to the results.
+func (m Model) focus(i int) Model {
+	if m.focusedInput < len(m.inputs) {
+		m.inputs[m.focusedInput].Blur()
+		m.inputs[m.focusedInput].PromptStyle = m.theme.faint
+	}
+	m.focusedInput = i
+	if i < len(m.inputs) {
+		m.inputs[i].Focus()
+		m.inputs[i].PromptStyle = m.theme.prompt
+	}
 	return m
 }
 
-func (m Model) View() string {
-	if m.loading {
-		return "Loading..."
+// typed records a change to the fields and schedules a search for when
+// typing pauses.
+func (m Model) typed() (Model, tea.Cmd) {
+	m.edits++
+	m.toResults = false
+	edit := m.edits
+	return m, tea.Tick(typingDelay, func(time.Time) tea.Msg {
+		return typedMsg{edit: edit}
+	})
+}
+
+// searchWords starts a search for the query in the fields, showing its
+// first page.
+func (m Model) searchWords() (Model, tea.Cmd) {
+	m = m.checkFields()
+	m.flags.SingleLetter = m.inputs[singleLetterField].Value()
+	m.flags.SixCharString = m.inputs[sixCharStringField].Value()
+	m.flags.Length = 0
+	m.errorMessage = ""
+
+	if m.flags.SingleLetter == "" || m.fieldsInvalid() {
+		// Nothing to search for, clear the results
+		m.cancelSearch()
+		m.seq++
+		m.loading = false
+		m.query = woordsoek.Query{}
+		return m.clearResults(), nil
+	}
+	if s := m.inputs[lengthField].Value(); s != "" {
+		// Only digits get past checkFields
+		m.flags.Length, _ = strconv.Atoi(s)
 	}
 
-	if m.errorMessage != "" {
-		return "Error: " + m.errorMessage + "\nPress 'esc' to quit."
+	slog.Info("Input values",
+		"SingleLetter", m.flags.SingleLetter,
+		"SixCharString", m.flags.SixCharString,
+		"Length", m.flags.Length,
+		"lang", m.locale,
+	)
+
+	m.query = woordsoek.Query{
+		Locale:        m.locale,
+		SingleLetter:  m.flags.SingleLetter,
+		SixCharString: m.flags.SixCharString,
+		Length:        m.flags.Length,
 	}
+	return m.fetchResults()
+}
 
-	if m.currentState == done {
-		if len(m.results) == 0 {
-			return "No matching words found.\nPress 'esc' to quit."
+// fetchResults asks the engine for the words matching the query, replacing
+// any search still running.
+func (m Model) fetchResults() (Model, tea.Cmd) {
+	m.cancelSearch()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelSearch = cancel
+	m.seq++
+	m.loading = true
+	m.progress = woordsoek.Progress{}
+
+	// Only the latest report is kept, the engine must never wait for the
+	// screen to catch up
+	updates := make(chan woordsoek.Progress, 1)
+	done := make(chan struct{})
+	ctx = woordsoek.WithProgress(ctx, func(p woordsoek.Progress) {
+		select {
+		case <-updates:
+		default:
+		}
+		select {
+		case updates <- p:
+		default:
 		}
+	})
+
+	engine, seq, q := m.engine, m.seq, m.query
+	search := func() tea.Msg {
+		defer close(done)
+		// Every word is fetched rather than a page at a time: the filter,
+		// the selection, copying and exporting all work on the whole
+		// result, as the hints always did. The list pages through the words
+		// itself, and the engine keeps the result cached either way.
+		// Sorting by length keeps the words of a length together.
+		page, err := engine.SearchPage(ctx, q, woordsoek.PageRequest{Sort: woordsoek.SortLengthAsc})
+		if err != nil {
+			return resultsMsg{seq: seq, err: err}
+		}
+		return resultsMsg{seq: seq, page: page, hints: woordsoek.NewHints(page.Words, q)}
+	}
+	return m, tea.Batch(m.spinner.Tick, search, waitForProgress(seq, updates, done))
+}
 
-		// Render the current page of results using the paginator
-		start, end := m.paginator.GetSliceBounds(len(m.results))
-		var b strings.Builder
-		b.WriteString("\nMatching Words:\n\n")
-		for _, item := range m.results[start:end] {
-			b.WriteString("  • " + item + "\n")
+// waitForProgress waits for the next progress report of search number seq,
+// or for the search to finish.
+func waitForProgress(seq int, updates <-chan woordsoek.Progress, done <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case p := <-updates:
+			return progressMsg{seq: seq, progress: p, updates: updates, done: done}
+		case <-done:
+			return nil
 		}
-		b.WriteString("\n" + m.paginator.View())
-		b.WriteString("\n\nPress 'esc' to quit. Press 'tab' to restart.\n")
-		return b.String()
 	}
+}
 
-	var b strings.Builder
-	b.WriteString("Input Values (Press 'Enter' to continue):\n\n")
+// cancel stops the search that is running. Its results, should any still
+// arrive, are discarded.
+func (m Model) cancel() Model {
+	m.cancelSearch()
+	m.seq++
+	m.loading = false
+	m.errorMessage = m.friendlyError(errors.Wrap(errors.CodeCancelled, "search cancelled", context.Canceled))
+	return m.clearResults()
+}
 
-	for i := range m.inputs {
-		if m.currentState <= inputLength && i > 2 {
-			break
+// clearResults forgets the results and whatever was selected from them.
+func (m Model) clearResults() Model {
+	m.results, m.total = nil, 0
+	m.hints = woordsoek.Hints{}
+	m.selected = nil
+	m.list.ResetFilter()
+	m.list.SetItems(nil)
+	if m.focusedInput == resultsPane {
+		m = m.focus(lengthField)
+	}
+	return m
+}
+
+// showResults shows the words found by the latest search. A filter being
+// used carries over to the new words.
+func (m Model) showResults(msg resultsMsg) (Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.errorMessage = m.friendlyError(msg.err)
+		slog.Error("Error searching for words", "error", msg.err)
+		return m.clearResults(), nil
+	}
+
+	m.results = msg.page.Words
+	m.total = msg.page.Total
+	m.hints = msg.hints
+	m.selected = make(map[string]bool)
+	m.starred = m.history.starredWords(m.query.Locale)
+
+	m.list.SetDelegate(m.wordDelegate())
+	items := make([]list.Item, len(m.results))
+	for i, word := range m.results {
+		items[i] = wordItem(word)
+	}
+	cmd := m.list.SetItems(items)
+	m.list.ResetSelected()
+	if m.total == 0 && m.focusedInput == resultsPane {
+		m = m.focus(lengthField)
+	}
+	if m.toResults && m.resultsShown() {
+		m = m.focus(resultsPane)
+	}
+	m.toResults = false
+	seq := m.seq
+	record := tea.Tick(historyDelay, func(time.Time) tea.Msg {
+		return recordMsg{seq: seq}
+	})
+	return m, tea.Batch(cmd, record)
+}
+
+// friendlyError turns an engine error into a message suitable for the user.
+// The full error is still logged by the caller.
+func (m Model) friendlyError(err error) string {
+	lang := m.query.Locale
+	switch errors.CodeOf(err) {
+	case errors.CodeDictionaryNotFound:
+		return "There is no dictionary for language '" + lang + "'. Press '" + m.keys.Dictionary.Help().Key + "' to pick another one."
+	case errors.CodeDictionaryCorrupt:
+		return "The dictionary for language '" + lang + "' could not be read."
+	case errors.CodeInvalidQuery:
+		var ce *errors.CustomError
+		if errors.As(err, &ce) {
+			return "Please check your input: " + ce.Message + "."
 		}
+		return "Please check your input."
+	case errors.CodeCancelled:
+		return "The search was cancelled."
+	default:
+		return "Something went wrong while searching for words."
+	}
+}
+
+// View draws the screen, preceded by an OSC52 sequence still to be sent. The
+// sequence takes no room, and going out with a frame it cannot end up in
+// the middle of one.
+func (m Model) View() string {
+	return m.osc52 + m.screen()
+}
+
+// screen draws what is on screen in the current state.
+func (m Model) screen() string {
+	switch m.currentState {
+	case pickingLocale:
+		return m.picker.View()
+	case browsingFavourites:
+		return m.favourites.View()
+	}
+
+	var b strings.Builder
+	b.WriteString(m.theme.title.Render("Dictionary: "+woordsoek.LocaleName(m.locale)+" ("+m.locale+")") + "\n\n")
+	for i := range m.inputs {
 		b.WriteString(m.inputs[i].View())
 		if i == m.focusedInput {
 			b.WriteString(" ←")
 		}
+		if i < len(m.fieldErrors) && m.fieldErrors[i] != "" {
+			b.WriteString("  " + m.theme.err.Render("✗ "+m.fieldErrors[i]))
+		} else if i == sixCharStringField && m.letterWarning != "" {
+			b.WriteString("  " + m.theme.warn.Render("! "+m.letterWarning))
+		}
 		b.WriteString("\n")
 	}
+	b.WriteString("\n")
+
+	switch {
+	case m.showHelp:
+		b.WriteString(m.help.FullHelpView(m.keys.fullHelp()) + "\n")
+	case m.errorMessage != "":
+		b.WriteString(m.theme.err.Render("Error: "+m.errorMessage) + "\n")
+	case m.loading && m.progress.Total > 0:
+		// Only a search that reads or scans the dictionary reports progress
+		label := "Searching"
+		if m.progress.Stage == woordsoek.StageLoading {
+			label = "Loading dictionary"
+		}
+		b.WriteString(m.spinner.View() + " " + label + "... " + m.progressBar.ViewAs(m.progress.Fraction()) + "\n")
+	case m.loading && m.total == 0:
+		b.WriteString(m.spinner.View() + " Searching...\n")
+	case m.fieldsInvalid():
+		b.WriteString("Please correct the fields marked ✗ to search.\n")
+	case m.query.SingleLetter == "":
+		b.WriteString("Type a single letter and the letters words may use to search.\n")
+	case m.total == 0:
+		b.WriteString("No matching words found.\n")
+	default:
+		// Earlier results stay on screen while searching again
+		status := ""
+		if m.loading {
+			status = " " + m.spinner.View()
+		}
+		if m.showHints {
+			b.WriteString(m.theme.title.Render("Hints ("+strconv.Itoa(m.total)+" words)") + status + "\n\n")
+			b.WriteString(hintsView(m.hints))
+			break
+		}
+		results := m.list
+		results.Title = "Matching Words (" + strconv.Itoa(m.total) + ")" + status
+		if m.history.starredQuery(m.query) {
+			results.Title += " ★"
+		}
+		if m.focusedInput == resultsPane {
+			results.Title += " ←"
+		}
+		b.WriteString(results.View() + "\n")
+	}
 
-	b.WriteString("\nPress 'esc' to quit, 'tab' to restart.")
-
+	if m.currentState == exporting {
+		b.WriteString("\n" + m.exportInput.View() + "\n")
+		if m.exportError != "" {
+			b.WriteString(m.theme.err.Render("Error: "+m.exportError) + "\n")
+		}
+	}
+	b.WriteString("\n" + m.help.ShortHelpView(m.shortHelp()) + "\n")
 	return b.String()
 }
@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+// newTestModel creates a model that remembers its choices in a temporary
+// directory.
+func newTestModel(t *testing.T) Model {
+	t.Helper()
+	return InitializeModel(loadTestConfig(t, "--locale", "xx"), Flags{})
+}
+
+// loadTestConfig loads the configuration given by args, with an empty
+// dictionary directory and a temporary directory to remember choices in.
+func loadTestConfig(t *testing.T, args ...string) *config.Config {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("WOORDSOEK_CONFIG", "")
+	cfg, _, err := config.Load("test", append([]string{"--dictionary-dir", t.TempDir()}, args...))
+	if err != nil {
+		t.Fatalf("config.Load(%q) returned an error: %v", args, err)
+	}
+	return cfg
+}
+
+func TestLiveSearchDiscardsStaleResults(t *testing.T) {
+	m := newTestModel(t)
+
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	update(tea.KeyMsg{Type: tea.KeyTab})
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+
+	// Only the pause after the last change starts a search
+	update(typedMsg{edit: 1})
+	if m.loading {
+		t.Fatal("A search started while typing continued")
+	}
+	update(typedMsg{edit: 2})
+	if !m.loading || m.query.SingleLetter != "o" || m.query.SixCharString != "d" {
+		t.Fatalf("After typing paused loading = %v with query %+v; expected a search for o and d", m.loading, m.query)
+	}
+
+	update(resultsMsg{seq: m.seq - 1, page: woordsoek.Page{Words: []string{"stale"}, Total: 1}})
+	if !m.loading || m.results != nil {
+		t.Errorf("Stale results were shown: %v", m.results)
+	}
+
+	expected := []string{"do", "odd"}
+	update(resultsMsg{seq: m.seq, page: woordsoek.Page{Words: expected, Total: 2}})
+	if m.loading || !reflect.DeepEqual(m.results, expected) || m.total != 2 {
+		t.Errorf("Results = %v (total %d, loading %v); expected %v", m.results, m.total, m.loading, expected)
+	}
+}
+
+func TestEscCancelsSearch(t *testing.T) {
+	m := newTestModel(t)
+
+	update := func(msg tea.Msg) tea.Cmd {
+		t.Helper()
+		next, cmd := m.Update(msg)
+		m = next.(Model)
+		return cmd
+	}
+
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	update(typedMsg{edit: m.edits})
+	if !m.loading {
+		t.Fatal("No search started")
+	}
+
+	report := woordsoek.Progress{Stage: woordsoek.StageScanning, Scanned: 50, Total: 200}
+	update(progressMsg{seq: m.seq, progress: report})
+	if m.progress != report {
+		t.Errorf("Progress = %+v; expected %+v", m.progress, report)
+	}
+
+	seq := m.seq
+	if cmd := update(tea.KeyMsg{Type: tea.KeyEsc}); cmd != nil {
+		if _, ok := cmd().(tea.QuitMsg); ok {
+			t.Fatal("Esc quit while a search was running")
+		}
+	}
+	if m.loading || m.errorMessage != "The search was cancelled." {
+		t.Errorf("After esc loading = %v with error %q; expected the search to be cancelled", m.loading, m.errorMessage)
+	}
+
+	update(resultsMsg{seq: seq, page: woordsoek.Page{Words: []string{"late"}, Total: 1}})
+	if m.results != nil {
+		t.Errorf("Results of the cancelled search were shown: %v", m.results)
+	}
+
+	cmd := update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("Esc did not quit once idle")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Esc did not quit once idle")
+	}
+}
+
+func TestToggleHints(t *testing.T) {
+	m := newTestModel(t)
+
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	update(typedMsg{edit: m.edits})
+	words := []string{"door", "adore"}
+	hints := woordsoek.NewHints(words, m.query)
+	update(resultsMsg{seq: m.seq, page: woordsoek.Page{Words: words, Total: 2}, hints: hints})
+
+	if view := m.View(); !strings.Contains(view, "4 letters (1)") || !strings.Contains(view, "adore") {
+		t.Errorf("The words are not grouped by length:\n%s", view)
+	}
+	update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if view := m.View(); !strings.Contains(view, "Two letter list") || strings.Contains(view, "adore") {
+		t.Errorf("ctrl+t did not swap the words for hints:\n%s", view)
+	}
+}
+
+func TestFlagsSearchStraightAway(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.Default()
+	cfg.DictionaryDir = t.TempDir()
+
+	// Without flags the first run asks for a dictionary
+	if m := InitializeModel(cfg, Flags{}); m.currentState != pickingLocale {
+		t.Errorf("The first run without flags is in state %d; expected the locale picker", m.currentState)
+	}
+
+	m := InitializeModel(cfg, Flags{SingleLetter: "O", SixCharString: "aedr", Length: 4})
+	if m.currentState != editing {
+		t.Fatalf("The first run with flags is in state %d; expected the search", m.currentState)
+	}
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	update(typedMsg{edit: m.edits})
+	expected := woordsoek.Query{Locale: cfg.Locale, SingleLetter: "o", SixCharString: "aedr", Length: 4}
+	if !m.loading || m.query != expected {
+		t.Fatalf("After starting loading = %v with query %+v; expected a search for %+v", m.loading, m.query, expected)
+	}
+
+	words := []string{"door", "road"}
+	update(resultsMsg{seq: m.seq, page: woordsoek.Page{Words: words, Total: 2}, hints: woordsoek.NewHints(words, m.query)})
+	if m.focusedInput != resultsPane {
+		t.Errorf("Focus = %d after the results of the flags; expected the results", m.focusedInput)
+	}
+
+	// Later searches leave the focus where it is
+	m = m.focus(lengthField)
+	update(typedMsg{edit: m.edits})
+	update(resultsMsg{seq: m.seq, page: woordsoek.Page{Words: words, Total: 2}, hints: woordsoek.NewHints(words, m.query)})
+	if m.focusedInput != lengthField {
+		t.Errorf("Focus = %d after searching again; expected the length field", m.focusedInput)
+	}
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+// The most characters the letters and length fields take. Letters typed
+// twice are dropped, so maxLetters is only reached by the largest alphabets.
+// The single letter has no limit, as letters with a vowel sign or accent
+// take more than one character; checkFields reports more than one letter.
+const (
+	maxLetters = 32
+	maxLength  = 2
+)
+
+// normaliseField lower-cases field i the way the dictionary's language does,
+// composes letters typed with separate accents, and drops letters typed
+// twice in the letters field. The cursor stays after the same letter.
+func (m Model) normaliseField(i int) Model {
+	if i == lengthField {
+		return m
+	}
+	value := m.inputs[i].Value()
+	pos := m.inputs[i].Position()
+
+	seen := make(map[string]bool)
+	var kept []rune
+	cursor, start := 0, 0
+	for _, letter := range splitLetters(value) {
+		typed := utf8.RuneCountInString(letter)
+		letter = woordsoek.NormaliseLetters(m.locale, letter)
+		if i != sixCharStringField || !seen[letter] {
+			seen[letter] = true
+			kept = append(kept, []rune(letter)...)
+		}
+		if start < pos {
+			cursor = len(kept)
+		}
+		start += typed
+	}
+	if string(kept) != value {
+		m.inputs[i].SetValue(string(kept))
+		m.inputs[i].SetCursor(cursor)
+	}
+	return m
+}
+
+// splitLetters splits s into letters, each a character with the marks that
+// combine with it, such as vowel signs and accents typed separately.
+func splitLetters(s string) []string {
+	var letters []string
+	for _, r := range s {
+		if len(letters) > 0 && combining(r) {
+			letters[len(letters)-1] += string(r)
+			continue
+		}
+		letters = append(letters, string(r))
+	}
+	return letters
+}
+
+// combining reports whether r combines with the character before it.
+func combining(r rune) bool {
+	return unicode.IsMark(r) || unicode.Is(unicode.Inherited, r)
+}
+
+// checkFields normalises every field and notes what is wrong with each, to
+// be shown beside it. A single letter that is also one of the letters is
+// allowed, but warned about.
+func (m Model) checkFields() Model {
+	for i := range m.inputs {
+		m = m.normaliseField(i)
+	}
+	m.fieldErrors = make([]string, len(m.inputs))
+	m.letterWarning = ""
+
+	alphabet := woordsoek.LocaleAlphabet(m.locale)
+	letters := "letters"
+	if alphabet.Name != "" {
+		letters = alphabet.Name + " letters"
+	}
+
+	single := m.inputs[singleLetterField].Value()
+	if single != "" && len(notIn(alphabet, single)) > 0 {
+		m.fieldErrors[singleLetterField] = "must be one of the " + letters
+	} else if len(splitLetters(single)) > 1 {
+		m.fieldErrors[singleLetterField] = "must be a single letter"
+	}
+	if bad := notIn(alphabet, m.inputs[sixCharStringField].Value()); len(bad) > 0 {
+		m.fieldErrors[sixCharStringField] = "use " + letters + " only, not " + joinOr(bad)
+	} else if single != "" && strings.Contains(m.inputs[sixCharStringField].Value(), single) {
+		m.letterWarning = single + " is already the single letter"
+	}
+	for _, r := range m.inputs[lengthField].Value() {
+		if r < '0' || r > '9' {
+			m.fieldErrors[lengthField] = "must be a whole number"
+			break
+		}
+	}
+	return m
+}
+
+// fieldsInvalid reports whether any field has an error.
+func (m Model) fieldsInvalid() bool {
+	for _, err := range m.fieldErrors {
+		if err != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// notIn returns the characters of s that are not letters of alphabet, each
+// once.
+func notIn(alphabet woordsoek.Alphabet, s string) []string {
+	var bad []string
+	for _, r := range s {
+		if !alphabet.Contains(r) {
+			c := string(r)
+			if unicode.IsSpace(r) {
+				c = "spaces"
+			}
+			if !slices.Contains(bad, c) {
+				bad = append(bad, c)
+			}
+		}
+	}
+	return bad
+}
+
+// joinOr lists items as in "1, 2 or 3".
+func joinOr(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCheckFields(t *testing.T) {
+	tests := []struct {
+		locale  string
+		fields  [3]string
+		checked string
+		errors  string
+		warning string
+	}{
+		{"af-za", [3]string{"O", "AEdDoR", "5"}, "o|aedor|5", "||", "o is already the single letter"},
+		{"af-za", [3]string{"Ê", "Ëe", ""}, "ê|ëe|", "||", ""},
+		// Accents typed separately are composed, then count as one letter
+		{"af-za", [3]string{"E\u0302", "e\u0302êe\u0308e", ""}, "ê|êëe|", "||", "ê is already the single letter"},
+		{"af-za", [3]string{"ab", "", ""}, "ab||", "must be a single letter||", ""},
+		// A vowel sign belongs to its letter, and may follow other letters too
+		{"ne", [3]string{"कि", "मिकि", ""}, "कि|मिकि|", "||", "कि is already the single letter"},
+		{"af-za", [3]string{"1", "ab1ж2", "5x"}, "1|ab1ж2|5x", "must be one of the Latin letters|use Latin letters only, not 1, ж or 2|must be a whole number", ""},
+		{"ru", [3]string{"Ж", "a ДАД", ""}, "ж|a да|", "|use Cyrillic letters only, not a or spaces|", ""},
+		{"tlh", [3]string{"q", "Qo'", ""}, "q|qo'|", "|use letters only, not '|", ""},
+	}
+	for _, test := range tests {
+		m := newTestModel(t)
+		m.locale = test.locale
+		for i, value := range test.fields {
+			m.inputs[i].SetValue(value)
+		}
+		m = m.checkFields()
+
+		var values []string
+		for i := range m.inputs {
+			values = append(values, m.inputs[i].Value())
+		}
+		if checked := strings.Join(values, "|"); checked != test.checked {
+			t.Errorf("checkFields(%q) in %s left the fields %q; expected %q", test.fields, test.locale, checked, test.checked)
+		}
+		if errors := strings.Join(m.fieldErrors, "|"); errors != test.errors {
+			t.Errorf("checkFields(%q) in %s found errors %q; expected %q", test.fields, test.locale, errors, test.errors)
+		}
+		if m.letterWarning != test.warning {
+			t.Errorf("checkFields(%q) in %s warned %q; expected %q", test.fields, test.locale, m.letterWarning, test.warning)
+		}
+	}
+}
+
+func TestInvalidFieldsDoNotSearch(t *testing.T) {
+	m := newTestModel(t)
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	typeText := func(s string) {
+		t.Helper()
+		for _, r := range s {
+			update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+
+	typeText("O")
+	update(tea.KeyMsg{Type: tea.KeyTab})
+	typeText("aaDd")
+	update(tea.KeyMsg{Type: tea.KeyTab})
+	typeText("4x7")
+	if m.inputs[sixCharStringField].Value() != "ad" || m.inputs[lengthField].Value() != "4x" {
+		t.Errorf("Typing gave the letters %q and length %q; expected ad and 4x (two characters at most)",
+			m.inputs[sixCharStringField].Value(), m.inputs[lengthField].Value())
+	}
+
+	update(typedMsg{edit: m.edits})
+	if m.loading {
+		t.Error("A search started with an invalid length")
+	}
+	if view := m.View(); !strings.Contains(view, "✗ must be a whole number") {
+		t.Errorf("The length error is not shown beside the field:\n%s", view)
+	}
+
+	update(tea.KeyMsg{Type: tea.KeyBackspace})
+	update(typedMsg{edit: m.edits})
+	if !m.loading || m.query.SingleLetter != "o" || m.query.SixCharString != "ad" || m.query.Length != 4 {
+		t.Errorf("After fixing the length loading = %v with query %+v; expected a search for o, ad and 4", m.loading, m.query)
+	}
+
+	// A vowel sign typed after the single letter is added to it, while
+	// another letter replaces it
+	m = m.focus(singleLetterField)
+	for _, test := range []struct{ typed, expected string }{{"क", "क"}, {"ि", "कि"}, {"म", "म"}} {
+		typeText(test.typed)
+		if single := m.inputs[singleLetterField].Value(); single != test.expected {
+			t.Errorf("Typing %q gave the single letter %q; expected %q", test.typed, single, test.expected)
+		}
+	}
+}
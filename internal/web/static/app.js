@@ -0,0 +1,222 @@
+"use strict";
+
+// The web UI of woordsoek. It lists the dictionaries from /v1/locales and
+// searches with /search, asking for NDJSON so that every word arrives with
+// its length and pangram flag.
+
+const cells = [...document.querySelectorAll(".cell")].sort(
+  (a, b) => a.dataset.index - b.dataset.index,
+);
+const locale = document.getElementById("locale");
+const statusLine = document.getElementById("status");
+const output = document.getElementById("output");
+let words = [];
+
+function apiHeaders(extra) {
+  const headers = { ...extra };
+  const key = localStorage.getItem("woordsoek.apiKey");
+  if (key) {
+    headers["X-API-Key"] = key;
+  }
+  return headers;
+}
+
+// request fetches url, asking once for an API key when the server needs one.
+async function request(url, headers) {
+  let response = await fetch(url, { headers: apiHeaders(headers) });
+  if (response.status === 401) {
+    const key = prompt("This server needs an API key:");
+    if (key) {
+      localStorage.setItem("woordsoek.apiKey", key);
+      response = await fetch(url, { headers: apiHeaders(headers) });
+    }
+  }
+  if (!response.ok) {
+    let detail = response.statusText;
+    try {
+      const problem = await response.json();
+      detail = problem.detail || problem.title || detail;
+    } catch (_) {
+      // Not a problem document, keep the status text
+    }
+    throw new Error(detail);
+  }
+  return response;
+}
+
+async function loadLocales() {
+  const response = await request("/v1/locales");
+  const { default: fallback, locales } = await response.json();
+  const remembered = new URLSearchParams(location.search).get("locale") ||
+    localStorage.getItem("woordsoek.locale") || fallback;
+  for (const info of locales) {
+    const option = new Option(`${info.name} (${info.locale})`, info.locale);
+    locale.add(option);
+  }
+  if (remembered && locales.some((info) => info.locale === remembered)) {
+    locale.value = remembered;
+  }
+}
+
+function letters() {
+  return cells.map((cell) => cell.value.toLowerCase());
+}
+
+async function search() {
+  const [centre, ...outer] = letters();
+  if (!centre) {
+    statusLine.textContent = "Enter the centre letter first.";
+    cells[0].focus();
+    return;
+  }
+
+  statusLine.textContent = "Searching…";
+  const params = new URLSearchParams({
+    singleLetter: centre,
+    sixCharString: outer.join(""),
+  });
+  try {
+    const response = await request(`/search?${params}`, {
+      Accept: "application/x-ndjson",
+      "X-Locale": locale.value,
+    });
+    const body = await response.text();
+    words = body.split("\n").filter(Boolean).map((line) => JSON.parse(line));
+    render();
+    statusLine.textContent = "";
+    history.replaceState(null, "", shareURL());
+  } catch (err) {
+    statusLine.textContent = `Could not search: ${err.message}`;
+  }
+}
+
+// render groups the words by length, longest first, and highlights pangrams.
+function render() {
+  const groups = document.getElementById("groups");
+  groups.replaceChildren();
+  document.getElementById("count").textContent =
+    `${words.length} word${words.length === 1 ? "" : "s"}, ` +
+    `${words.filter((w) => w.pangram).length} pangram(s)`;
+
+  const byLength = new Map();
+  for (const word of words) {
+    if (!byLength.has(word.length)) {
+      byLength.set(word.length, []);
+    }
+    byLength.get(word.length).push(word);
+  }
+
+  for (const length of [...byLength.keys()].sort((a, b) => b - a)) {
+    const group = document.createElement("div");
+    group.className = "group";
+    const heading = document.createElement("h2");
+    heading.textContent = `${length} letters (${byLength.get(length).length})`;
+    const list = document.createElement("ul");
+    for (const word of byLength.get(length)) {
+      const item = document.createElement("li");
+      item.textContent = word.word;
+      if (word.pangram) {
+        item.className = "pangram";
+        item.title = "Pangram: uses every letter";
+      }
+      list.append(item);
+    }
+    group.append(heading, list);
+    groups.append(group);
+  }
+  output.hidden = false;
+}
+
+function shareURL() {
+  const [centre, ...outer] = letters();
+  const url = new URL(location.href);
+  url.search = new URLSearchParams({
+    c: centre,
+    l: outer.join(""),
+    locale: locale.value,
+  }).toString();
+  return url.toString();
+}
+
+async function copy(text, what) {
+  try {
+    await navigator.clipboard.writeText(text);
+    statusLine.textContent = `Copied ${what}.`;
+  } catch (_) {
+    statusLine.textContent = `Could not copy ${what}.`;
+  }
+}
+
+// Typing moves to the next cell, backspace on an empty cell to the previous
+// one, and pasting letters fills the cells from the one pasted into.
+cells.forEach((cell, i) => {
+  cell.addEventListener("input", () => {
+    cell.value = cell.value.slice(-1).toLowerCase();
+    if (cell.value && i < cells.length - 1) {
+      cells[i + 1].focus();
+    }
+  });
+  cell.addEventListener("keydown", (event) => {
+    if (event.key === "Backspace" && !cell.value && i > 0) {
+      cells[i - 1].focus();
+    }
+  });
+  cell.addEventListener("paste", (event) => {
+    event.preventDefault();
+    const pasted = [...event.clipboardData.getData("text").replace(/\s/g, "")];
+    pasted.slice(0, cells.length - i).forEach((letter, j) => {
+      cells[i + j].value = letter.toLowerCase();
+    });
+  });
+});
+
+document.getElementById("puzzle").addEventListener("submit", (event) => {
+  event.preventDefault();
+  search();
+});
+
+document.getElementById("clear").addEventListener("click", () => {
+  cells.forEach((cell) => (cell.value = ""));
+  output.hidden = true;
+  cells[0].focus();
+});
+
+document.getElementById("copy").addEventListener("click", () => {
+  copy(words.map((w) => w.word).join("\n"), "the words");
+});
+
+document.getElementById("share").addEventListener("click", async () => {
+  const url = shareURL();
+  if (navigator.share) {
+    try {
+      await navigator.share({ title: "Woordsoek", url });
+      return;
+    } catch (_) {
+      // Cancelled or unsupported, fall back to copying the link
+    }
+  }
+  copy(url, "the link");
+});
+
+locale.addEventListener("change", () => {
+  localStorage.setItem("woordsoek.locale", locale.value);
+  if (!output.hidden) {
+    search();
+  }
+});
+
+// A shared link fills in the letters and searches straight away.
+loadLocales()
+  .then(() => {
+    const params = new URLSearchParams(location.search);
+    const shared = (params.get("c") || "") + (params.get("l") || "");
+    if (shared) {
+      [...shared].slice(0, cells.length).forEach((letter, i) => {
+        cells[i].value = letter;
+      });
+      search();
+    }
+  })
+  .catch((err) => {
+    statusLine.textContent = `Could not load the dictionaries: ${err.message}`;
+  });
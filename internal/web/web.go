@@ -0,0 +1,29 @@
+// Package web holds the single-page web UI served by the API server.
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the web UI. It talks to the API through /v1/locales and
+// /search on the same server.
+func Handler() fiber.Handler {
+	root, err := fs.Sub(static, "static")
+	if err != nil {
+		// The embedded directory is fixed at compile time
+		panic(err)
+	}
+	return filesystem.New(filesystem.Config{
+		Root:   http.FS(root),
+		Index:  "index.html",
+		MaxAge: 3600,
+	})
+}
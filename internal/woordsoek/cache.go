@@ -0,0 +1,108 @@
+package woordsoek
+
+import (
+	"container/list"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// resultCache is a fixed-size LRU cache of search results. Keys include the
+// dictionary version, so results of a replaced dictionary are never served;
+// purge frees them early when a dictionary is reloaded.
+type resultCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key    string
+	locale string
+	words  []string
+}
+
+// newResultCache returns nil when size is not positive, which disables
+// caching.
+func newResultCache(size int) *resultCache {
+	if size <= 0 {
+		return nil
+	}
+	return &resultCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *resultCache) get(key string) ([]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).words, true
+}
+
+func (c *resultCache) add(key, locale string, words []string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*cacheEntry).words = words
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, locale: locale, words: words})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// purge removes every result for locale.
+func (c *resultCache) purge(locale string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		if entry := el.Value.(*cacheEntry); entry.locale == locale {
+			c.order.Remove(el)
+			delete(c.entries, entry.key)
+		}
+		el = next
+	}
+}
+
+// Key returns q in a normalized form: queries with the same key always have
+// the same results. The letters only act as a set of allowed characters, so
+// they are lower-cased, de-duplicated and sorted. The single letter is kept
+// as is since it is matched case-sensitively.
+func (q Query) Key() string {
+	letters := []rune(strings.ToLower(q.SixCharString))
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+
+	var unique []rune
+	for i, r := range letters {
+		if i == 0 || r != letters[i-1] {
+			unique = append(unique, r)
+		}
+	}
+	return q.Locale + "|" + q.SingleLetter + "|" + string(unique) + "|" + strconv.Itoa(q.Length)
+}
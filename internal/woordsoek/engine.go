@@ -0,0 +1,295 @@
+package woordsoek
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Engine searches the dictionaries in a directory. Each locale is loaded into
+// an Index on first use and kept in memory, so it is shared safely between
+// the API handlers, the TUI and any other front end. With WithResultCache,
+// recent results are also kept so that repeated queries skip the scan.
+type Engine struct {
+	dir     string
+	metrics *Metrics
+	cache   *resultCache
+
+	mu      sync.Mutex
+	indexes map[string]*indexEntry
+
+	// reloadMu serializes reloads so that the last one to start is the one
+	// left in place.
+	reloadMu sync.Mutex
+}
+
+// indexEntry is a loaded or loading index. ready is closed once index or err
+// is set.
+type indexEntry struct {
+	ready chan struct{}
+	index *Index
+	err   error
+}
+
+// Option configures an Engine.
+type Option func(*Engine)
+
+// WithMetrics records engine metrics in m.
+func WithMetrics(m *Metrics) Option {
+	return func(e *Engine) {
+		e.metrics = m
+	}
+}
+
+// WithResultCache keeps the results of the last size distinct queries. A size
+// of 0 disables the cache.
+func WithResultCache(size int) Option {
+	return func(e *Engine) {
+		e.cache = newResultCache(size)
+	}
+}
+
+// Result holds the words found by Engine.Search. Version is the version of
+// the dictionary that was searched, and Cached reports whether the words came
+// from the result cache.
+type Result struct {
+	Words   []string
+	Version string
+	Cached  bool
+}
+
+// NewEngine creates an engine for the <locale>.txt dictionaries in dir.
+func NewEngine(dir string, opts ...Option) *Engine {
+	e := &Engine{
+		dir:     dir,
+		indexes: make(map[string]*indexEntry),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Search runs q against the dictionary for q.Locale. The returned words may
+// be modified freely by the caller.
+func (e *Engine) Search(ctx context.Context, q Query) (result Result, err error) {
+	ctx, span := tracer.Start(ctx, "Engine.Search", trace.WithAttributes(
+		attribute.String("dictionary.locale", q.Locale),
+		attribute.String("search.single_letter", q.SingleLetter),
+		attribute.String("search.letters", q.SixCharString),
+		attribute.Int("search.length", q.Length),
+	))
+	defer func() { endSpan(span, err) }()
+
+	if err := q.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	ix, err := e.Index(ctx, q.Locale)
+	if err != nil {
+		return Result{}, err
+	}
+	return e.search(ctx, ix, q)
+}
+
+// search runs q against ix, using the result cache if there is one.
+func (e *Engine) search(ctx context.Context, ix *Index, q Query) (Result, error) {
+	result := Result{Version: ix.Version}
+
+	key := ix.Version + "|" + q.Key()
+	words, ok := e.cache.get(key)
+	if e.cache != nil {
+		e.metrics.observeCacheLookup("result", ok)
+	}
+	if !ok {
+		var err error
+		words, err = ix.Search(ctx, q)
+		if err != nil {
+			return Result{}, err
+		}
+		e.cache.add(key, q.Locale, words)
+	}
+	result.Words = slices.Clone(words)
+	result.Cached = ok
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.Int("search.results", len(words)),
+		attribute.Bool("search.cached", ok),
+	)
+	e.metrics.observeResults(q.Locale, len(words))
+	return result, nil
+}
+
+// Index returns the index for locale, loading it if needed. Concurrent callers
+// share a single load, which carries on in the background even if ctx is
+// cancelled so that later searches can still use it.
+func (e *Engine) Index(ctx context.Context, locale string) (*Index, error) {
+	if err := validateLocale(locale); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	entry, ok := e.indexes[locale]
+	if !ok {
+		entry = &indexEntry{ready: make(chan struct{})}
+		e.indexes[locale] = entry
+		go e.load(context.WithoutCancel(ctx), locale, entry)
+	}
+	e.mu.Unlock()
+	e.metrics.observeCacheLookup("index", ok)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("dictionary.cached", ok))
+
+	select {
+	case <-entry.ready:
+		return entry.index, entry.err
+	case <-ctx.Done():
+		return nil, errors.Wrap(errors.CodeCancelled, "waiting for dictionary "+locale, ctx.Err())
+	}
+}
+
+func (e *Engine) load(ctx context.Context, locale string, entry *indexEntry) {
+	defer close(entry.ready)
+
+	start := time.Now()
+	ix, err := LoadIndex(ctx, e.path(locale))
+	if err != nil {
+		// Forget failed loads so that the next search tries again
+		e.mu.Lock()
+		if e.indexes[locale] == entry {
+			delete(e.indexes, locale)
+		}
+		e.mu.Unlock()
+		entry.err = err
+		return
+	}
+	ix.Locale = locale
+
+	e.metrics.observeLoad(locale, time.Since(start), ix)
+	entry.index = ix
+}
+
+// Reload reads the dictionary for locale again and swaps the new index in
+// once it is complete. Searches keep using the previous index until then, and
+// keep using it if the new one cannot be loaded.
+func (e *Engine) Reload(ctx context.Context, locale string) (*Index, error) {
+	if err := validateLocale(locale); err != nil {
+		return nil, err
+	}
+
+	e.reloadMu.Lock()
+	defer e.reloadMu.Unlock()
+
+	start := time.Now()
+	ix, err := LoadIndex(ctx, e.path(locale))
+	if err != nil {
+		e.metrics.observeReload(locale, false)
+		return nil, err
+	}
+	ix.Locale = locale
+
+	entry := &indexEntry{ready: make(chan struct{}), index: ix}
+	close(entry.ready)
+
+	e.mu.Lock()
+	e.indexes[locale] = entry
+	e.mu.Unlock()
+	e.cache.purge(locale)
+
+	e.metrics.observeLoad(locale, time.Since(start), ix)
+	e.metrics.observeReload(locale, true)
+	return ix, nil
+}
+
+// Invalidate forgets the index and cached results for locale, so that the
+// next search reads the dictionary from disk again.
+func (e *Engine) Invalidate(locale string) {
+	e.mu.Lock()
+	delete(e.indexes, locale)
+	e.mu.Unlock()
+	e.cache.purge(locale)
+}
+
+// Loaded reports whether the index for locale is in memory.
+func (e *Engine) Loaded(locale string) bool {
+	_, ok := e.loadedIndex(locale)
+	return ok
+}
+
+// loadedIndex returns the index for locale if it is in memory, without
+// loading it.
+func (e *Engine) loadedIndex(locale string) (*Index, bool) {
+	e.mu.Lock()
+	entry, ok := e.indexes[locale]
+	e.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	select {
+	case <-entry.ready:
+		return entry.index, entry.err == nil
+	default:
+		return nil, false
+	}
+}
+
+// LoadedLocales lists the locales whose index is in memory, sorted.
+func (e *Engine) LoadedLocales() []string {
+	e.mu.Lock()
+	candidates := make([]string, 0, len(e.indexes))
+	for locale := range e.indexes {
+		candidates = append(candidates, locale)
+	}
+	e.mu.Unlock()
+
+	var locales []string
+	for _, locale := range candidates {
+		if e.Loaded(locale) {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Locales lists the locales that have a dictionary in the engine's directory.
+func (e *Engine) Locales() ([]string, error) {
+	entries, err := os.ReadDir(e.dir)
+	if err != nil {
+		return nil, errors.Wrap(errors.CodeDictionaryNotFound, "reading dictionary directory "+e.dir, err)
+	}
+
+	var locales []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".txt" {
+			continue
+		}
+		locales = append(locales, strings.TrimSuffix(entry.Name(), ".txt"))
+	}
+	sort.Strings(locales)
+	return locales, nil
+}
+
+func (e *Engine) path(locale string) string {
+	return filepath.Join(e.dir, locale+".txt")
+}
+
+// validateLocale rejects locales that could escape the dictionary directory.
+func validateLocale(locale string) error {
+	if locale == "" {
+		return errors.New(errors.CodeInvalidQuery, "a locale is required")
+	}
+	if strings.ContainsAny(locale, `/\`) || strings.Contains(locale, "..") {
+		return errors.New(errors.CodeInvalidQuery, "locale "+locale+" is not valid")
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package woordsoek
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+)
+
+func TestEngineSearch(t *testing.T) {
+	LoadVowelForms()
+
+	dir := t.TempDir()
+	words := []string{"hello", "wörld", "world", "word", "example"}
+	if err := os.WriteFile(filepath.Join(dir, "xx.txt"), []byte(strings.Join(words, "\n")), 0644); err != nil {
+		t.Fatalf("Failed to write test dictionary: %v", err)
+	}
+	engine := NewEngine(dir)
+
+	// Concurrent first searches share one load
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := engine.Search(context.Background(), Query{Locale: "xx", SingleLetter: "w", SixCharString: "orldö"})
+			if err != nil {
+				t.Errorf("Search returned an error: %v", err)
+				return
+			}
+			if expected := []string{"word", "world"}; !reflect.DeepEqual(result.Words, expected) {
+				t.Errorf("Search = %v; expected %v", result.Words, expected)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !engine.Loaded("xx") {
+		t.Error("Loaded(xx) = false after a search")
+	}
+
+	locales, err := engine.Locales()
+	if err != nil || !reflect.DeepEqual(locales, []string{"xx"}) {
+		t.Errorf("Locales() = %v, %v; expected [xx]", locales, err)
+	}
+
+	tests := []struct {
+		locale   string
+		expected error
+	}{
+		{"missing", errors.ErrDictionaryNotFound},
+		{"../xx", errors.ErrInvalidQuery},
+		{"", errors.ErrInvalidQuery},
+	}
+	for _, test := range tests {
+		_, err := engine.Search(context.Background(), Query{Locale: test.locale, SingleLetter: "w"})
+		if !errors.Is(err, test.expected) {
+			t.Errorf("Search(locale %q) returned %v; expected %v", test.locale, err, test.expected)
+		}
+	}
+}
+
+func TestEngineResultCache(t *testing.T) {
+	LoadVowelForms()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "xx.txt")
+	if err := os.WriteFile(path, []byte("word\nworld\nrow"), 0644); err != nil {
+		t.Fatalf("Failed to write test dictionary: %v", err)
+	}
+	engine := NewEngine(dir, WithResultCache(2))
+	ctx := context.Background()
+
+	tests := []struct {
+		letters string
+		cached  bool
+	}{
+		{"orld", false},
+		{"dlro", true},  // same letters in another order
+		{"DLROo", true}, // case and duplicates do not matter
+		{"orl", false},
+		{"or", false}, // evicts the orld results
+		{"orld", false},
+	}
+	var version string
+	for _, test := range tests {
+		result, err := engine.Search(ctx, Query{Locale: "xx", SingleLetter: "w", SixCharString: test.letters})
+		if err != nil {
+			t.Fatalf("Search(%q) returned an error: %v", test.letters, err)
+		}
+		if result.Cached != test.cached {
+			t.Errorf("Search(%q).Cached = %v; expected %v", test.letters, result.Cached, test.cached)
+		}
+		version = result.Version
+	}
+
+	// Callers may modify the words without affecting the cache
+	result, _ := engine.Search(ctx, Query{Locale: "xx", SingleLetter: "w", SixCharString: "orld"})
+	result.Words[0] = "changed"
+	result, _ = engine.Search(ctx, Query{Locale: "xx", SingleLetter: "w", SixCharString: "orld"})
+	if expected := []string{"row", "word", "world"}; !result.Cached || !reflect.DeepEqual(result.Words, expected) {
+		t.Errorf("Search = %v (cached %v); expected cached %v", result.Words, result.Cached, expected)
+	}
+
+	// A reloaded dictionary gets a new version and fresh results
+	if err := os.WriteFile(path, []byte("word\nworld\nwold"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite test dictionary: %v", err)
+	}
+	engine.Invalidate("xx")
+	result, err := engine.Search(ctx, Query{Locale: "xx", SingleLetter: "w", SixCharString: "orld"})
+	if err != nil {
+		t.Fatalf("Search after Invalidate returned an error: %v", err)
+	}
+	if expected := []string{"wold", "word", "world"}; result.Cached || result.Version == version || !reflect.DeepEqual(result.Words, expected) {
+		t.Errorf("Search after Invalidate = %v (cached %v, version %s); expected fresh %v with a version other than %s",
+			result.Words, result.Cached, result.Version, expected, version)
+	}
+}
+
+func TestEngineReload(t *testing.T) {
+	LoadVowelForms()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "xx.txt")
+	if err := os.WriteFile(path, []byte("word\nworld"), 0644); err != nil {
+		t.Fatalf("Failed to write test dictionary: %v", err)
+	}
+	engine := NewEngine(dir, WithResultCache(8))
+	ctx := context.Background()
+	q := Query{Locale: "xx", SingleLetter: "w", SixCharString: "orld"}
+
+	before, err := engine.Search(ctx, q)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("word\nworld\nwold"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite test dictionary: %v", err)
+	}
+	ix, err := engine.Reload(ctx, "xx")
+	if err != nil {
+		t.Fatalf("Reload returned an error: %v", err)
+	}
+	if ix.Version == before.Version || ix.Len() != 3 {
+		t.Errorf("Reload = version %s with %d words; expected a new version with 3 words", ix.Version, ix.Len())
+	}
+
+	after, err := engine.Search(ctx, q)
+	if expected := []string{"wold", "word", "world"}; err != nil || after.Cached || after.Version != ix.Version || !reflect.DeepEqual(after.Words, expected) {
+		t.Errorf("Search after Reload = %+v, %v; expected fresh %v from version %s", after, err, expected, ix.Version)
+	}
+
+	// A failed reload keeps the current index
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove test dictionary: %v", err)
+	}
+	if _, err := engine.Reload(ctx, "xx"); !errors.Is(err, errors.ErrDictionaryNotFound) {
+		t.Errorf("Reload of a removed dictionary returned %v; expected %v", err, errors.ErrDictionaryNotFound)
+	}
+	if result, err := engine.Search(ctx, q); err != nil || result.Version != ix.Version {
+		t.Errorf("Search after a failed Reload = %+v, %v; expected version %s", result, err, ix.Version)
+	}
+	if locales := engine.LoadedLocales(); !reflect.DeepEqual(locales, []string{"xx"}) {
+		t.Errorf("LoadedLocales() = %v; expected [xx]", locales)
+	}
+}
+
+func TestEngineWatch(t *testing.T) {
+	LoadVowelForms()
+	defer func(d time.Duration) { watchDebounce = d }(watchDebounce)
+	watchDebounce = 10 * time.Millisecond
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "xx.txt")
+	if err := os.WriteFile(path, []byte("word"), 0644); err != nil {
+		t.Fatalf("Failed to write test dictionary: %v", err)
+	}
+	engine := NewEngine(dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ix, err := engine.Index(ctx, "xx")
+	if err != nil {
+		t.Fatalf("Index returned an error: %v", err)
+	}
+
+	watching := make(chan error, 1)
+	go func() { watching <- engine.Watch(ctx) }()
+	// Give the watcher time to subscribe before changing the file
+	time.Sleep(50 * time.Millisecond)
+
+	if err := os.WriteFile(path, []byte("word\nworld"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite test dictionary: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		current, err := engine.Index(ctx, "xx")
+		if err == nil && current.Version != ix.Version && current.Len() == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Dictionary was not reloaded after its file changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-watching; err != nil {
+		t.Errorf("Watch returned an error: %v", err)
+	}
+}
+
+func TestEngineProgress(t *testing.T) {
+	LoadVowelForms()
+
+	dir := t.TempDir()
+	var words []string
+	for i := 0; i < 3*progressEvery; i++ {
+		words = append(words, "word")
+	}
+	content := strings.Join(words, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "xx.txt"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test dictionary: %v", err)
+	}
+	engine := NewEngine(dir)
+
+	var mu sync.Mutex
+	var reports []Progress
+	ctx := WithProgress(context.Background(), func(p Progress) {
+		mu.Lock()
+		reports = append(reports, p)
+		mu.Unlock()
+	})
+	if _, err := engine.Search(ctx, Query{Locale: "xx", SingleLetter: "w", SixCharString: "ord"}); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	last := map[Stage]Progress{}
+	for _, p := range reports {
+		if prev, ok := last[p.Stage]; ok && p.Scanned < prev.Scanned {
+			t.Errorf("Progress went back from %d to %d while %s", prev.Scanned, p.Scanned, p.Stage)
+		}
+		last[p.Stage] = p
+	}
+	for _, stage := range []Stage{StageLoading, StageScanning} {
+		p, ok := last[stage]
+		if !ok {
+			t.Errorf("No progress reported while %s", stage)
+			continue
+		}
+		if p.Total != int64(len(content)) || p.Fraction() != 1 {
+			t.Errorf("Last %s progress = %+v; expected all %d bytes", stage, p, len(content))
+		}
+	}
+}
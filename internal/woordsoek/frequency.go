@@ -0,0 +1,61 @@
+package woordsoek
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+)
+
+// frequencyPath returns the optional word frequency file that accompanies
+// the dictionary at path: af-za.freq next to af-za.txt.
+func frequencyPath(path string) string {
+	return strings.TrimSuffix(path, ".txt") + ".freq"
+}
+
+// loadFrequencies reads a frequency file with one "word count" pair per line
+// and writes its content to hash. Words are folded and lower-cased like the
+// search results they are looked up for. A missing file yields no
+// frequencies.
+func loadFrequencies(path string, hash io.Writer) (map[string]int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(errors.CodeDictionaryCorrupt, "error opening frequency file "+path, err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	freq := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		hash.Write(scanner.Bytes())
+		hash.Write([]byte{'\n'})
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		count, err := strconv.Atoi(fields[len(fields)-1])
+		if len(fields) != 2 || err != nil || count < 0 {
+			return nil, errors.Wrap(errors.CodeDictionaryCorrupt, "frequency file "+path+" line "+strconv.Itoa(line)+" is not \"word count\"", err)
+		}
+		freq[fold(strings.ToLower(fields[0]))] += count
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(errors.CodeDictionaryCorrupt, "error reading frequency file "+path, err)
+	}
+	return freq, nil
+}
+
+// Frequency returns how common word is according to the dictionary's
+// frequency file, or 0 when it is not listed or there is no such file.
+func (ix *Index) Frequency(word string) int {
+	return ix.freq[fold(strings.ToLower(word))]
+}
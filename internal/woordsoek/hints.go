@@ -0,0 +1,115 @@
+package woordsoek
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Group is the words of one length.
+type Group struct {
+	Length int      `json:"length"`
+	Words  []string `json:"words"`
+}
+
+// GroupByLength buckets words by their length in letters, shortest first.
+// Words keep their order within a group.
+func GroupByLength(words []string) []Group {
+	var groups []Group
+	index := make(map[int]int)
+	for _, word := range words {
+		n := utf8.RuneCountInString(word)
+		i, ok := index[n]
+		if !ok {
+			i = len(groups)
+			index[n] = i
+			groups = append(groups, Group{Length: n})
+		}
+		groups[i].Words = append(groups[i].Words, word)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Length < groups[j].Length })
+	return groups
+}
+
+// Hints describes a set of results the way the Spelling Bee hint page does,
+// without giving away the words themselves.
+type Hints struct {
+	Words    int `json:"words"`
+	Points   int `json:"points"`
+	Pangrams int `json:"pangrams"`
+
+	// Lengths are the columns of the grid, shortest first. LengthTotals
+	// holds the number of words of each.
+	Lengths      []int     `json:"lengths"`
+	LengthTotals []int     `json:"lengthTotals"`
+	Grid         []HintRow `json:"grid"`
+
+	Prefixes []PrefixCount `json:"prefixes"`
+}
+
+// HintRow counts the words starting with Letter, by length. Counts lines up
+// with Hints.Lengths.
+type HintRow struct {
+	Letter string `json:"letter"`
+	Counts []int  `json:"counts"`
+	Total  int    `json:"total"`
+}
+
+// PrefixCount is the number of words starting with Prefix, the first two
+// letters of a word.
+type PrefixCount struct {
+	Prefix string `json:"prefix"`
+	Count  int    `json:"count"`
+}
+
+// NewHints counts the words found for q by first letter, length and two
+// letter prefix. Letters are compared in lower case.
+func NewHints(words []string, q Query) Hints {
+	hints := Hints{Words: len(words), Lengths: []int{}, LengthTotals: []int{}, Grid: []HintRow{}, Prefixes: []PrefixCount{}}
+
+	lengths := make(map[int]int)
+	grid := make(map[string]map[int]int)
+	prefixes := make(map[string]int)
+	for _, word := range words {
+		hints.Points += Score(word, q)
+		if IsPangram(word, q) {
+			hints.Pangrams++
+		}
+
+		runes := []rune(strings.ToLower(word))
+		if len(runes) == 0 {
+			continue
+		}
+		lengths[len(runes)]++
+		letter := string(runes[0])
+		if grid[letter] == nil {
+			grid[letter] = make(map[int]int)
+		}
+		grid[letter][len(runes)]++
+		prefixes[string(runes[:min(2, len(runes))])]++
+	}
+
+	for n := range lengths {
+		hints.Lengths = append(hints.Lengths, n)
+	}
+	sort.Ints(hints.Lengths)
+	for _, n := range hints.Lengths {
+		hints.LengthTotals = append(hints.LengthTotals, lengths[n])
+	}
+
+	for letter, counts := range grid {
+		row := HintRow{Letter: letter, Counts: make([]int, len(hints.Lengths))}
+		for i, n := range hints.Lengths {
+			row.Counts[i] = counts[n]
+			row.Total += counts[n]
+		}
+		hints.Grid = append(hints.Grid, row)
+	}
+	sort.Slice(hints.Grid, func(i, j int) bool { return hints.Grid[i].Letter < hints.Grid[j].Letter })
+
+	for prefix, count := range prefixes {
+		hints.Prefixes = append(hints.Prefixes, PrefixCount{Prefix: prefix, Count: count})
+	}
+	sort.Slice(hints.Prefixes, func(i, j int) bool { return hints.Prefixes[i].Prefix < hints.Prefixes[j].Prefix })
+	return hints
+}
@@ -0,0 +1,41 @@
+package woordsoek
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupByLength(t *testing.T) {
+	groups := GroupByLength([]string{"adore", "door", "dör", "odor", "rodeo"})
+	expected := []Group{
+		{Length: 3, Words: []string{"dör"}},
+		{Length: 4, Words: []string{"door", "odor"}},
+		{Length: 5, Words: []string{"adore", "rodeo"}},
+	}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("GroupByLength = %v; expected %v", groups, expected)
+	}
+}
+
+func TestNewHints(t *testing.T) {
+	q := Query{SingleLetter: "o", SixCharString: "ader"}
+	hints := NewHints([]string{"adore", "door", "Dare", "odor", "road", "rode", "adored"}, q)
+
+	expected := Hints{
+		Words:        7,
+		Points:       (5 + pangramBonus) + 1 + 1 + 1 + 1 + 1 + (6 + pangramBonus),
+		Pangrams:     2,
+		Lengths:      []int{4, 5, 6},
+		LengthTotals: []int{5, 1, 1},
+		Grid: []HintRow{
+			{Letter: "a", Counts: []int{0, 1, 1}, Total: 2},
+			{Letter: "d", Counts: []int{2, 0, 0}, Total: 2},
+			{Letter: "o", Counts: []int{1, 0, 0}, Total: 1},
+			{Letter: "r", Counts: []int{2, 0, 0}, Total: 2},
+		},
+		Prefixes: []PrefixCount{{"ad", 2}, {"da", 1}, {"do", 1}, {"od", 1}, {"ro", 2}},
+	}
+	if !reflect.DeepEqual(hints, expected) {
+		t.Errorf("NewHints = %+v; expected %+v", hints, expected)
+	}
+}
@@ -0,0 +1,275 @@
+package woordsoek
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the engine spans. It follows whatever tracer provider is
+// installed globally, and is a no-op until one is.
+var tracer = otel.Tracer("github.com/jvanrhyn/woordsoek/internal/woordsoek")
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Query describes a word search. Words must contain SingleLetter and consist
+// only of SingleLetter and the letters in SixCharString. A Length of 0 means
+// any length.
+type Query struct {
+	Locale        string
+	SingleLetter  string
+	SixCharString string
+	Length        int
+}
+
+// Validate checks the parts of the query that do not depend on a dictionary.
+func (q Query) Validate() error {
+	if q.SingleLetter == "" {
+		return errors.New(errors.CodeInvalidQuery, "a single letter is required")
+	}
+	if q.Length < 0 {
+		return errors.New(errors.CodeInvalidQuery, "length cannot be negative")
+	}
+	return nil
+}
+
+// Index is a dictionary held in memory so that it can be searched repeatedly
+// without reading the file again. Version identifies the dictionary content:
+// it is derived from a hash of the file and of its frequency file, if any,
+// and only changes when they do.
+type Index struct {
+	Locale   string
+	Path     string
+	Version  string
+	LoadedAt time.Time
+
+	words []string
+	lower []string
+	freq  map[string]int
+	size  int64
+}
+
+// LoadIndex reads the dictionary at path, one word per line, along with its
+// frequency file if there is one.
+func LoadIndex(ctx context.Context, path string) (ix *Index, err error) {
+	ctx, span := tracer.Start(ctx, "LoadIndex", trace.WithAttributes(attribute.String("dictionary.path", path)))
+	defer func() { endSpan(span, err) }()
+
+	// Open the file for reading
+	slog.DebugContext(ctx, "Opening file: "+path)
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Wrap(errors.CodeDictionaryNotFound, "dictionary "+path+" does not exist", err)
+		}
+		return nil, errors.Wrap(errors.CodeDictionaryCorrupt, "error opening dictionary "+path, err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	// The size is only needed for progress reports, so a failed stat is
+	// not fatal
+	progress := Progress{Stage: StageLoading}
+	if info, err := file.Stat(); err == nil {
+		progress.Total = info.Size()
+	}
+
+	ix = &Index{Path: path}
+	hash := sha256.New()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(errors.CodeCancelled, "loading dictionary cancelled", err)
+		}
+		if len(ix.words)%progressEvery == 0 {
+			progress.Scanned = ix.size
+			reportProgress(ctx, progress)
+		}
+		word := scanner.Text()
+		hash.Write(scanner.Bytes())
+		hash.Write([]byte{'\n'})
+		ix.words = append(ix.words, word)
+		ix.lower = append(ix.lower, strings.ToLower(word))
+		ix.size += int64(len(word)) + 1
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(errors.CodeDictionaryCorrupt, "error reading dictionary "+path, err)
+	}
+	progress.Scanned = max(ix.size, progress.Total)
+	reportProgress(ctx, progress)
+
+	if ix.freq, err = loadFrequencies(frequencyPath(path), hash); err != nil {
+		return nil, err
+	}
+
+	ix.Version = hex.EncodeToString(hash.Sum(nil))[:16]
+	ix.LoadedAt = time.Now()
+	span.SetAttributes(
+		attribute.Int("dictionary.words", ix.Len()),
+		attribute.String("dictionary.version", ix.Version),
+	)
+	return ix, nil
+}
+
+// Len returns the number of words in the index.
+func (ix *Index) Len() int {
+	return len(ix.words)
+}
+
+// MemoryBytes estimates the memory held by the index: the word bytes, the
+// lower-cased copies where they differ and the string headers.
+func (ix *Index) MemoryBytes() int64 {
+	const stringHeader = 16
+	total := int64(2 * stringHeader * len(ix.words))
+	for i, word := range ix.words {
+		total += int64(len(word))
+		if ix.lower[i] != word {
+			total += int64(len(ix.lower[i]))
+		}
+	}
+	return total
+}
+
+// Search returns the words in the index matching q, with vowel forms folded
+// to their base vowel, duplicates removed and sorted alphabetically.
+func (ix *Index) Search(ctx context.Context, q Query) ([]string, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	results, err := ix.lookup(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return finalize(ctx, results, q.Length), nil
+}
+
+// lookup scans the index for the words matching q.
+func (ix *Index) lookup(ctx context.Context, q Query) (results []string, err error) {
+	ctx, span := tracer.Start(ctx, "Index.lookup", trace.WithAttributes(
+		attribute.String("dictionary.locale", ix.Locale),
+		attribute.Int("dictionary.words", ix.Len()),
+	))
+	defer func() { endSpan(span, err) }()
+
+	allowedChars := strings.ToLower(q.SingleLetter + q.SixCharString)
+
+	// Per-word tracing is only done at debug level, and then only for a
+	// sample of the words so that a full scan does not flood the log.
+	traceWords := slog.Default().Enabled(ctx, slog.LevelDebug)
+
+	progress := Progress{Stage: StageScanning, Total: ix.size}
+	for i, word := range ix.words {
+		if i%progressEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, errors.Wrap(errors.CodeCancelled, "search cancelled", err)
+			}
+			reportProgress(ctx, progress)
+		}
+		progress.Scanned += int64(len(word)) + 1
+
+		outcome := "missing single letter"
+		if strings.Contains(word, q.SingleLetter) {
+			outcome = "invalid"
+			if containsOnly(ix.lower[i], allowedChars) {
+				outcome = "wrong length"
+				if q.Length == 0 || len(word) == q.Length {
+					results = append(results, word)
+					outcome = "added"
+				}
+			}
+		}
+
+		if traceWords && i%traceSample == 0 {
+			slog.DebugContext(ctx, "Checked word", "word", word, "outcome", outcome, "scanned", i)
+		}
+	}
+
+	reportProgress(ctx, progress)
+	span.SetAttributes(attribute.Int("search.matches", len(results)))
+	return results, nil
+}
+
+// containsOnly reports whether the non-empty word consists only of runes in
+// allowed. Both are expected to be lower case already.
+func containsOnly(word, allowed string) bool {
+	if word == "" {
+		return false
+	}
+	for _, char := range word {
+		if !strings.ContainsRune(allowed, char) {
+			return false
+		}
+	}
+	return true
+}
+
+// fold replaces the vowel forms in word with their base vowel.
+func fold(word string) string {
+	for vowel, forms := range vowelForms {
+		for _, form := range forms {
+			word = strings.ReplaceAll(word, string(form), string(vowel))
+		}
+	}
+	return word
+}
+
+// finalize applies the post-processing shared by every search: the minimum
+// length rule, vowel folding, de-duplication and sorting.
+func finalize(ctx context.Context, results []string, length int) []string {
+	_, span := tracer.Start(ctx, "fold")
+
+	// Filter results for words 4 letters and longer only if length is not 0
+	var filteredResults []string
+	for _, word := range results {
+		if length != 0 && len(word) < 4 {
+			continue
+		}
+		filteredResults = append(filteredResults, word)
+	}
+
+	// Replace vowel forms with the base vowel in the results
+	for i, word := range filteredResults {
+		filteredResults[i] = fold(word)
+	}
+
+	// Remove duplicate words from the results
+	uniqueResults := make(map[string]struct{})
+	for _, word := range filteredResults {
+		uniqueResults[word] = struct{}{}
+	}
+
+	results = make([]string, 0, len(uniqueResults))
+	for word := range uniqueResults {
+		results = append(results, word)
+	}
+	span.End()
+
+	// Sort the results alphabetically
+	_, span = tracer.Start(ctx, "sort", trace.WithAttributes(attribute.Int("search.results", len(results))))
+	sort.Strings(results)
+	span.End()
+
+	return results
+}
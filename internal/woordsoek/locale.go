@@ -0,0 +1,159 @@
+package woordsoek
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"unicode"
+
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+	"golang.org/x/text/unicode/norm"
+)
+
+// LocaleInfo describes a dictionary. Words is only known once the dictionary
+// is loaded.
+type LocaleInfo struct {
+	Locale string `json:"locale"`
+	Name   string `json:"name"`
+	Loaded bool   `json:"loaded"`
+	Words  int    `json:"words,omitempty"`
+}
+
+// LocaleName returns the name of locale in its own language, such as
+// "Afrikaans (Suid-Afrika)" for af-za or "British English" for en-GB, or the
+// locale itself when it has no known name.
+func LocaleName(locale string) string {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return locale
+	}
+	name := display.Self.Name(tag)
+	if name == "" {
+		return locale
+	}
+
+	// Most regional variants are named after their language only, so add
+	// the region to tell them apart
+	base, _ := tag.Base()
+	region, confidence := tag.Region()
+	if confidence == language.Exact && name == display.Self.Name(language.Make(base.String())) {
+		if regionName := display.Regions(tag).Name(region); regionName != "" {
+			name += " (" + regionName + ")"
+		}
+	}
+	return name
+}
+
+// Alphabet is the script the words of a locale are written in, such as Latin
+// for af-za or Cyrillic for ru.
+type Alphabet struct {
+	// Name is the script's name in English, empty when it is not known.
+	Name   string
+	tables []*unicode.RangeTable
+}
+
+// LocaleAlphabet returns the script locale is written in. Any letter belongs
+// to the alphabet of a locale whose script is not known, such as tlh.
+func LocaleAlphabet(locale string) Alphabet {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return Alphabet{}
+	}
+	script, confidence := tag.Script()
+	if confidence == language.No {
+		return Alphabet{}
+	}
+	name := display.English.Scripts().Name(script)
+	if script.String() == "Kore" {
+		// Korean mixes Hangul with Han characters
+		return Alphabet{Name: name, tables: []*unicode.RangeTable{unicode.Hangul, unicode.Han}}
+	}
+	if table, ok := unicode.Scripts[name]; ok {
+		return Alphabet{Name: name, tables: []*unicode.RangeTable{table}}
+	}
+	return Alphabet{}
+}
+
+// Contains reports whether r is a letter of the alphabet. The vowel signs
+// and other marks that some scripts combine with letters count as letters,
+// as do the accents shared by every script, such as U+0302 in a decomposed ê.
+func (a Alphabet) Contains(r rune) bool {
+	if len(a.tables) == 0 {
+		return unicode.IsLetter(r) || unicode.IsMark(r)
+	}
+	return unicode.In(r, a.tables...) || unicode.Is(unicode.Inherited, r)
+}
+
+// NormaliseLetters lower-cases s the way the language of locale does, so
+// that a Turkish İ becomes i and I becomes ı, and composes accents typed
+// separately with their letter, as the dictionaries spell them.
+func NormaliseLetters(locale, s string) string {
+	return norm.NFC.String(cases.Lower(language.Make(locale)).String(s))
+}
+
+// LocaleInfos describes every dictionary in the engine's directory.
+func (e *Engine) LocaleInfos() ([]LocaleInfo, error) {
+	locales, err := e.Locales()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]LocaleInfo, len(locales))
+	for i, locale := range locales {
+		infos[i] = LocaleInfo{Locale: locale, Name: LocaleName(locale)}
+		if ix, ok := e.loadedIndex(locale); ok {
+			infos[i].Loaded = true
+			infos[i].Words = ix.Len()
+		}
+	}
+	return infos, nil
+}
+
+// WordCount returns the number of words in the dictionary for locale. When
+// the dictionary is not loaded the lines of its file are counted instead,
+// which is much cheaper than loading it.
+func (e *Engine) WordCount(locale string) (int, error) {
+	if err := validateLocale(locale); err != nil {
+		return 0, err
+	}
+	if ix, ok := e.loadedIndex(locale); ok {
+		return ix.Len(), nil
+	}
+
+	path := e.path(locale)
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, errors.Wrap(errors.CodeDictionaryNotFound, "dictionary "+path+" does not exist", err)
+		}
+		return 0, errors.Wrap(errors.CodeDictionaryCorrupt, "error opening dictionary "+path, err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	// Every line is a word, including a last one without a newline
+	count := 0
+	last := byte('\n')
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			count += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, errors.Wrap(errors.CodeDictionaryCorrupt, "error reading dictionary "+path, err)
+		}
+	}
+	if last != '\n' {
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,118 @@
+package woordsoek
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+)
+
+func TestLocaleName(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{"af-za", "Afrikaans (Suid-Afrika)"},
+		{"af", "Afrikaans"},
+		{"en-GB", "British English"},
+		{"es-AR", "español (Argentina)"},
+		{"tlh", "tlh"},
+		{"not a locale", "not a locale"},
+	}
+	for _, test := range tests {
+		if name := LocaleName(test.locale); name != test.expected {
+			t.Errorf("LocaleName(%q) = %q; expected %q", test.locale, name, test.expected)
+		}
+	}
+}
+
+func TestLocaleAlphabet(t *testing.T) {
+	tests := []struct {
+		locale   string
+		name     string
+		letters  string
+		excluded string
+	}{
+		{"af-za", "Latin", "aêëô\u0302", "жα1'"},
+		{"ru", "Cyrillic", "жяё", "a1"},
+		{"ka", "Georgian", "აბ", "a"},
+		{"ko", "Korean", "한국", "a"},
+		{"ne", "Devanagari", "नेपा", "a"},
+		{"tlh", "", "aжქ", "1-"},
+	}
+	for _, test := range tests {
+		alphabet := LocaleAlphabet(test.locale)
+		if alphabet.Name != test.name {
+			t.Errorf("LocaleAlphabet(%q).Name = %q; expected %q", test.locale, alphabet.Name, test.name)
+		}
+		for _, r := range test.letters {
+			if !alphabet.Contains(r) {
+				t.Errorf("LocaleAlphabet(%q).Contains(%q) = false; expected true", test.locale, r)
+			}
+		}
+		for _, r := range test.excluded {
+			if alphabet.Contains(r) {
+				t.Errorf("LocaleAlphabet(%q).Contains(%q) = true; expected false", test.locale, r)
+			}
+		}
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	dir := t.TempDir()
+	engine := NewEngine(dir)
+	tests := []struct {
+		content  string
+		expected int
+	}{
+		{"", 0},
+		{"word", 1},
+		{"word\n", 1},
+		{"word\nworld\nwold", 3},
+		{"word\nworld\nwold\n", 3},
+	}
+	for _, test := range tests {
+		if err := os.WriteFile(filepath.Join(dir, "xx.txt"), []byte(test.content), 0644); err != nil {
+			t.Fatalf("Failed to write test dictionary: %v", err)
+		}
+		if count, err := engine.WordCount("xx"); err != nil || count != test.expected {
+			t.Errorf("WordCount(%q) = %d, %v; expected %d", test.content, count, err, test.expected)
+		}
+	}
+
+	// A loaded dictionary reports the words in its index
+	LoadVowelForms()
+	if _, err := engine.Index(context.Background(), "xx"); err != nil {
+		t.Fatalf("Index returned an error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "xx.txt"), nil, 0644); err != nil {
+		t.Fatalf("Failed to empty test dictionary: %v", err)
+	}
+	if count, err := engine.WordCount("xx"); err != nil || count != 3 {
+		t.Errorf("WordCount of a loaded dictionary = %d, %v; expected 3", count, err)
+	}
+
+	if _, err := engine.WordCount("missing"); !errors.Is(err, errors.ErrDictionaryNotFound) {
+		t.Errorf("WordCount(missing) returned %v; expected %v", err, errors.ErrDictionaryNotFound)
+	}
+}
+
+func TestNormaliseLetters(t *testing.T) {
+	tests := []struct {
+		locale   string
+		letters  string
+		expected string
+	}{
+		{"af-za", "AEDR", "aedr"},
+		{"af-za", "E\u0302O\u0308", "êö"},
+		{"af-za", "Iİ", "ii̇"},
+		{"tr", "Iİ", "ıi"},
+	}
+	for _, test := range tests {
+		if letters := NormaliseLetters(test.locale, test.letters); letters != test.expected {
+			t.Errorf("NormaliseLetters(%q, %q) = %q; expected %q", test.locale, test.letters, letters, test.expected)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package woordsoek
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the Prometheus collectors of the engine. They are registered
+// on the registry passed to NewMetrics, so every server sharing that registry
+// (HTTP or gRPC) exposes the same engine metrics. A nil *Metrics records
+// nothing.
+type Metrics struct {
+	resultSize   *prometheus.HistogramVec
+	loadDuration *prometheus.HistogramVec
+	indexBytes   *prometheus.GaugeVec
+	indexWords   *prometheus.GaugeVec
+	cacheLookups *prometheus.CounterVec
+	info         *prometheus.GaugeVec
+	reloads      *prometheus.CounterVec
+}
+
+// NewMetrics creates the engine collectors and registers them on reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		resultSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "woordsoek",
+			Name:      "search_results",
+			Help:      "Number of words returned per search.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		}, []string{"locale"}),
+		loadDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "woordsoek",
+			Name:      "dictionary_load_duration_seconds",
+			Help:      "Time taken to load a dictionary into memory.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 10),
+		}, []string{"locale"}),
+		indexBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "woordsoek",
+			Name:      "index_memory_bytes",
+			Help:      "Estimated memory held by a loaded dictionary index.",
+		}, []string{"locale"}),
+		indexWords: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "woordsoek",
+			Name:      "index_words",
+			Help:      "Number of words in a loaded dictionary index.",
+		}, []string{"locale"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "woordsoek",
+			Name:      "cache_lookups_total",
+			Help:      "Cache lookups by cache and result (hit or miss).",
+		}, []string{"cache", "result"}),
+		info: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "woordsoek",
+			Name:      "dictionary_info",
+			Help:      "Always 1, labelled with the version of each loaded dictionary.",
+		}, []string{"locale", "version"}),
+		reloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "woordsoek",
+			Name:      "dictionary_reloads_total",
+			Help:      "Dictionary reloads by locale and result (ok or failed).",
+		}, []string{"locale", "result"}),
+	}
+
+	reg.MustRegister(m.resultSize, m.loadDuration, m.indexBytes, m.indexWords, m.cacheLookups, m.info, m.reloads)
+	return m
+}
+
+func (m *Metrics) observeResults(locale string, n int) {
+	if m == nil {
+		return
+	}
+	m.resultSize.WithLabelValues(locale).Observe(float64(n))
+}
+
+func (m *Metrics) observeLoad(locale string, d time.Duration, ix *Index) {
+	if m == nil {
+		return
+	}
+	m.loadDuration.WithLabelValues(locale).Observe(d.Seconds())
+	m.indexBytes.WithLabelValues(locale).Set(float64(ix.MemoryBytes()))
+	m.indexWords.WithLabelValues(locale).Set(float64(ix.Len()))
+	m.info.DeletePartialMatch(prometheus.Labels{"locale": locale})
+	m.info.WithLabelValues(locale, ix.Version).Set(1)
+}
+
+func (m *Metrics) observeReload(locale string, ok bool) {
+	if m == nil {
+		return
+	}
+	result := "failed"
+	if ok {
+		result = "ok"
+	}
+	m.reloads.WithLabelValues(locale, result).Inc()
+}
+
+func (m *Metrics) observeCacheLookup(cache string, hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(cache, result).Inc()
+}
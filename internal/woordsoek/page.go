@@ -0,0 +1,178 @@
+package woordsoek
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sort is the order of paged search results. Every order falls back to
+// alphabetical order for words that compare equal.
+type Sort string
+
+const (
+	SortAlpha      Sort = "alpha"
+	SortLengthAsc  Sort = "length_asc"
+	SortLengthDesc Sort = "length_desc"
+	SortScore      Sort = "score"     // highest Spelling Bee score first
+	SortFrequency  Sort = "frequency" // most common first, see Index.Frequency
+)
+
+// ParseSort returns the Sort named s; an empty s is SortAlpha.
+func ParseSort(s string) (Sort, error) {
+	switch Sort(s) {
+	case "":
+		return SortAlpha, nil
+	case SortAlpha, SortLengthAsc, SortLengthDesc, SortScore, SortFrequency:
+		return Sort(s), nil
+	}
+	return "", errors.New(errors.CodeInvalidQuery,
+		"sort must be alpha, length_asc, length_desc, score or frequency")
+}
+
+// PageRequest selects a page of search results. A Limit of 0 returns every
+// result after Cursor. Cursor is empty for the first page and otherwise the
+// Next value of the previous page.
+type PageRequest struct {
+	Sort   Sort
+	Limit  int
+	Cursor string
+}
+
+// Page is one page of search results. Total counts every result of the
+// query, and Next is the cursor of the following page, empty on the last.
+type Page struct {
+	Words   []string
+	Total   int
+	Next    string
+	Version string
+	Cached  bool
+}
+
+// cursor is the decoded form of a page cursor. It holds the last word of the
+// previous page rather than an offset, so paging carries on from the right
+// place even if the dictionary is reloaded in between.
+type cursor struct {
+	Query string `json:"q"`
+	Sort  Sort   `json:"s"`
+	After string `json:"a"`
+}
+
+// queryHash ties a cursor to the query it was issued for.
+func queryHash(q Query) string {
+	sum := sha256.Sum256([]byte(q.Key()))
+	return hex.EncodeToString(sum[:6])
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.After == "" {
+		return cursor{}, errors.Wrap(errors.CodeInvalidQuery, "the cursor is not valid", err)
+	}
+	return c, nil
+}
+
+// SearchPage runs q like Search and returns the page of results selected by
+// p in the requested order.
+func (e *Engine) SearchPage(ctx context.Context, q Query, p PageRequest) (page Page, err error) {
+	ctx, span := tracer.Start(ctx, "Engine.SearchPage", trace.WithAttributes(
+		attribute.String("dictionary.locale", q.Locale),
+		attribute.String("search.sort", string(p.Sort)),
+		attribute.Int("search.limit", p.Limit),
+	))
+	defer func() { endSpan(span, err) }()
+
+	if err := q.Validate(); err != nil {
+		return Page{}, err
+	}
+	if p.Sort == "" {
+		p.Sort = SortAlpha
+	}
+	if _, err := ParseSort(string(p.Sort)); err != nil {
+		return Page{}, err
+	}
+	if p.Limit < 0 {
+		return Page{}, errors.New(errors.CodeInvalidQuery, "limit cannot be negative")
+	}
+	var after *cursor
+	if p.Cursor != "" {
+		c, err := decodeCursor(p.Cursor)
+		if err != nil {
+			return Page{}, err
+		}
+		if c.Query != queryHash(q) || c.Sort != p.Sort {
+			return Page{}, errors.New(errors.CodeInvalidQuery, "the cursor belongs to another query or sort order")
+		}
+		after = &c
+	}
+
+	ix, err := e.Index(ctx, q.Locale)
+	if err != nil {
+		return Page{}, err
+	}
+	result, err := e.search(ctx, ix, q)
+	if err != nil {
+		return Page{}, err
+	}
+
+	words := result.Words
+	less := lessFunc(p.Sort, q, ix)
+	if p.Sort != SortAlpha {
+		sort.SliceStable(words, func(i, j int) bool { return less(words[i], words[j]) })
+	}
+
+	page = Page{Total: len(words), Version: result.Version, Cached: result.Cached}
+	start := 0
+	if after != nil {
+		start = sort.Search(len(words), func(i int) bool { return less(after.After, words[i]) })
+	}
+	end := len(words)
+	if p.Limit > 0 && start+p.Limit < end {
+		end = start + p.Limit
+		page.Next = encodeCursor(cursor{Query: queryHash(q), Sort: p.Sort, After: words[end-1]})
+	}
+	page.Words = words[start:end]
+	return page, nil
+}
+
+// lessFunc returns the ordering for s. It is a strict total order over
+// distinct words, which lets a cursor word that is no longer in the results
+// still find its place.
+func lessFunc(s Sort, q Query, ix *Index) func(a, b string) bool {
+	var key func(word string) int
+	switch s {
+	case SortLengthAsc:
+		key = func(word string) int { return utf8.RuneCountInString(word) }
+	case SortLengthDesc:
+		key = func(word string) int { return -utf8.RuneCountInString(word) }
+	case SortScore:
+		key = func(word string) int { return -Score(word, q) }
+	case SortFrequency:
+		key = func(word string) int { return -ix.Frequency(word) }
+	default:
+		return func(a, b string) bool { return a < b }
+	}
+	return func(a, b string) bool {
+		if ka, kb := key(a), key(b); ka != kb {
+			return ka < kb
+		}
+		return a < b
+	}
+}
@@ -0,0 +1,103 @@
+package woordsoek
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+)
+
+func TestScore(t *testing.T) {
+	q := Query{SingleLetter: "o", SixCharString: "aedr"}
+	tests := []struct {
+		word     string
+		pangram  bool
+		expected int
+	}{
+		{"rod", false, 0},
+		{"road", false, 1},
+		{"rodeo", false, 5},
+		{"adore", true, 12},
+		{"Adored", true, 13},
+	}
+	for _, test := range tests {
+		if pangram := IsPangram(test.word, q); pangram != test.pangram {
+			t.Errorf("IsPangram(%q) = %v; expected %v", test.word, pangram, test.pangram)
+		}
+		if score := Score(test.word, q); score != test.expected {
+			t.Errorf("Score(%q) = %d; expected %d", test.word, score, test.expected)
+		}
+	}
+}
+
+func TestSearchPage(t *testing.T) {
+	LoadVowelForms()
+
+	dir := t.TempDir()
+	words := "adore\nroad\nrode\ndoor\nodor\nrodeo\nadored\nrod"
+	if err := os.WriteFile(filepath.Join(dir, "xx.txt"), []byte(words), 0644); err != nil {
+		t.Fatalf("Failed to write test dictionary: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "xx.freq"), []byte("door 90\nroad 50\nRóde 20\nrode 5\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test frequencies: %v", err)
+	}
+	engine := NewEngine(dir, WithResultCache(8))
+	ctx := context.Background()
+	q := Query{Locale: "xx", SingleLetter: "o", SixCharString: "aedr"}
+
+	tests := []struct {
+		sort     Sort
+		limit    int
+		expected []string
+	}{
+		{SortAlpha, 3, []string{"adore", "adored", "door", "odor", "road", "rod", "rode", "rodeo"}},
+		{SortLengthAsc, 3, []string{"rod", "door", "odor", "road", "rode", "adore", "rodeo", "adored"}},
+		{SortLengthDesc, 0, []string{"adored", "adore", "rodeo", "door", "odor", "road", "rode", "rod"}},
+		{SortScore, 5, []string{"adored", "adore", "rodeo", "door", "odor", "road", "rode", "rod"}},
+		{SortFrequency, 2, []string{"door", "road", "rode", "adore", "adored", "odor", "rod", "rodeo"}},
+	}
+	for _, test := range tests {
+		var got []string
+		p := PageRequest{Sort: test.sort, Limit: test.limit}
+		for pages := 0; ; pages++ {
+			if pages > len(test.expected) {
+				t.Fatalf("SearchPage(%s) keeps returning pages", test.sort)
+			}
+			page, err := engine.SearchPage(ctx, q, p)
+			if err != nil {
+				t.Fatalf("SearchPage(%s) returned an error: %v", test.sort, err)
+			}
+			if page.Total != len(test.expected) || (test.limit > 0 && len(page.Words) > test.limit) {
+				t.Errorf("SearchPage(%s) returned %d of %d words; expected at most %d of %d",
+					test.sort, len(page.Words), page.Total, test.limit, len(test.expected))
+			}
+			got = append(got, page.Words...)
+			if page.Next == "" {
+				break
+			}
+			p.Cursor = page.Next
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("SearchPage(%s) pages = %v; expected %v", test.sort, got, test.expected)
+		}
+	}
+
+	first, _ := engine.SearchPage(ctx, q, PageRequest{Limit: 2})
+	for _, p := range []PageRequest{
+		{Sort: "random"},
+		{Limit: -1},
+		{Cursor: "not-a-cursor"},
+		{Sort: SortScore, Cursor: first.Next},
+	} {
+		if _, err := engine.SearchPage(ctx, q, p); !errors.Is(err, errors.ErrInvalidQuery) {
+			t.Errorf("SearchPage(%+v) returned %v; expected %v", p, err, errors.ErrInvalidQuery)
+		}
+	}
+	other := Query{Locale: "xx", SingleLetter: "d", SixCharString: "aeor"}
+	if _, err := engine.SearchPage(ctx, other, PageRequest{Cursor: first.Next}); !errors.Is(err, errors.ErrInvalidQuery) {
+		t.Errorf("SearchPage with the cursor of another query returned %v; expected %v", err, errors.ErrInvalidQuery)
+	}
+}
@@ -0,0 +1,48 @@
+package woordsoek
+
+import "context"
+
+// Stage is the part of a search that a Progress report is about.
+type Stage string
+
+const (
+	// StageLoading is reading the dictionary from disk.
+	StageLoading Stage = "loading"
+	// StageScanning is matching the words of a loaded dictionary.
+	StageScanning Stage = "scanning"
+)
+
+// Progress reports how far a stage has got, in bytes of the dictionary.
+type Progress struct {
+	Stage   Stage
+	Scanned int64
+	Total   int64
+}
+
+// Fraction returns how much of the stage is done, between 0 and 1.
+func (p Progress) Fraction() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return min(float64(p.Scanned)/float64(p.Total), 1)
+}
+
+// progressEvery is how many words are read or matched between reports.
+const progressEvery = 1024
+
+type progressKey struct{}
+
+// WithProgress returns a context under which dictionary loads and scans call
+// report as they go. report is called from the goroutine doing the work and
+// must not block. A dictionary load is shared with other searches and carries
+// on when ctx is cancelled, so report may still be called after that.
+func WithProgress(ctx context.Context, report func(Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// reportProgress passes p to the reporter in ctx, if there is one.
+func reportProgress(ctx context.Context, p Progress) {
+	if report, ok := ctx.Value(progressKey{}).(func(Progress)); ok {
+		report(p)
+	}
+}
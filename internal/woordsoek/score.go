@@ -0,0 +1,41 @@
+package woordsoek
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// pangramBonus is added to the score of a word that uses every letter.
+const pangramBonus = 7
+
+// IsPangram reports whether word uses every letter of q: the single letter
+// and all of the letters in SixCharString.
+func IsPangram(word string, q Query) bool {
+	word = strings.ToLower(word)
+	for _, r := range strings.ToLower(q.SingleLetter + q.SixCharString) {
+		if !strings.ContainsRune(word, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Score rates word the way Spelling Bee does: nothing for words shorter than
+// four letters, one point for four-letter words, a point per letter for
+// longer words and a bonus for pangrams.
+func Score(word string, q Query) int {
+	n := utf8.RuneCountInString(word)
+	score := 0
+	switch {
+	case n < 4:
+		return 0
+	case n == 4:
+		score = 1
+	default:
+		score = n
+	}
+	if IsPangram(word, q) {
+		score += pangramBonus
+	}
+	return score
+}
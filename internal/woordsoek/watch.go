@@ -0,0 +1,93 @@
+package woordsoek
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long a dictionary must be left alone before it is
+// reloaded, so that a file being written in several steps is read once.
+var watchDebounce = 500 * time.Millisecond
+
+// Watch reloads loaded dictionaries when their file or frequency file in the
+// engine's directory changes, until ctx is done. Dictionaries that are not
+// loaded yet are left alone; they are read fresh on first use anyway.
+// Removing a file does not unload its dictionary.
+func (e *Engine) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// Watch the directory rather than the files so that editors that
+	// replace a file by renaming a new one over it are noticed.
+	if err := watcher.Add(e.dir); err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	pending := make(map[string]*time.Timer)
+	defer func() {
+		mu.Lock()
+		for _, timer := range pending {
+			timer.Stop()
+		}
+		mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Warn("Dictionary watcher error", "dir", e.dir, "error", err)
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			name := filepath.Base(event.Name)
+			ext := filepath.Ext(name)
+			if ext != ".txt" && ext != ".freq" {
+				continue
+			}
+			locale := strings.TrimSuffix(name, ext)
+			if !e.Loaded(locale) {
+				continue
+			}
+
+			mu.Lock()
+			if timer, ok := pending[locale]; ok {
+				timer.Reset(watchDebounce)
+			} else {
+				pending[locale] = time.AfterFunc(watchDebounce, func() {
+					mu.Lock()
+					delete(pending, locale)
+					mu.Unlock()
+
+					ix, err := e.Reload(ctx, locale)
+					if err != nil {
+						slog.Error("Failed to reload dictionary", "locale", locale, "error", err)
+						return
+					}
+					slog.Info("Reloaded dictionary", "locale", locale, "version", ix.Version, "words", ix.Len())
+				})
+			}
+			mu.Unlock()
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package woordsoek
 
 import (
+	"context"
 	"os"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/jvanrhyn/woordsoek/internal/errors"
 )
 
 func TestIsValidWord(t *testing.T) {
@@ -69,3 +72,37 @@ func TestSearchForMatchingWords(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchForMatchingWordsErrors(t *testing.T) {
+	tempFile := "test_errors.txt"
+	defer func(name string) {
+		_ = os.Remove(name)
+	}(tempFile)
+	if err := os.WriteFile(tempFile, []byte("hello\nworld"), 0644); err != nil {
+		t.Fatalf("Failed to write test words to file: %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		filename     string
+		singleLetter string
+		length       int
+		expected     error
+	}{
+		{"missing dictionary", context.Background(), "does_not_exist.txt", "h", 0, errors.ErrDictionaryNotFound},
+		{"empty letter", context.Background(), tempFile, "", 0, errors.ErrInvalidQuery},
+		{"negative length", context.Background(), tempFile, "h", -1, errors.ErrInvalidQuery},
+		{"cancelled", cancelled, tempFile, "h", 0, errors.ErrCancelled},
+	}
+
+	for _, test := range tests {
+		_, err := SearchForMatchingWordsContext(test.ctx, test.filename, test.singleLetter, "ello", test.length)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("%s: got error %v; expected %v", test.name, err, test.expected)
+		}
+	}
+}
@@ -1,29 +1,88 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"os"
-
-	_ "github.com/joho/godotenv/autoload"
+	"slices"
 
 	tea "github.com/charmbracelet/bubbletea"
-	configure "github.com/jvanrhyn/woordsoek/internal/config" // Correct import statement
+	"github.com/jvanrhyn/woordsoek/internal/config"
 	"github.com/jvanrhyn/woordsoek/internal/tui"
 	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
 )
 
 func main() {
-	logger := configure.SetupLogging()
+	cfg, search, command, args, err := parseArgs(os.Args[1:])
+	if err == nil {
+		err = tui.CheckKeys(cfg.TUI.Keys)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsage)
+	}
+
+	// woordsoek config show prints the effective configuration
+	if len(args) > 0 && args[0] == "config" {
+		if len(args) != 2 || args[1] != "show" {
+			fmt.Fprintln(os.Stderr, "usage: woordsoek config show")
+			os.Exit(exitUsage)
+		}
+		if err := cfg.Write(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logger, err := config.SetupLogging(cfg.Log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to set up logging:", err)
+		os.Exit(1)
+	}
 	slog.SetDefault(logger)
+	woordsoek.SetTraceSample(cfg.Log.TraceSample)
 	woordsoek.LoadVowelForms()
 
-	flags := tui.Flags{
-		Length: 0,
+	// woordsoek search prints the words found instead of starting the TUI
+	if command == "search" {
+		os.Exit(runSearch(context.Background(), cfg, search, os.Stdout, os.Stderr))
+	}
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "woordsoek: unknown command %q, use search or config show\n", args[0])
+		os.Exit(exitUsage)
 	}
 
-	p := tea.NewProgram(tui.InitializeModel(flags), tea.WithAltScreen())
+	p := tea.NewProgram(tui.InitializeModel(cfg, search.tuiFlags()), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		slog.Error(err.Error())
 		os.Exit(1)
 	}
 }
+
+// parseArgs loads the configuration from args, those after the program name,
+// and returns the command with the arguments left after the flags. woordsoek
+// search takes the configuration flags as well as the search flags, before
+// or after the command.
+func parseArgs(args []string) (cfg *config.Config, search searchFlags, command string, rest []string, err error) {
+	if len(args) > 0 && args[0] == "search" {
+		args, command = args[1:], "search"
+	}
+	cfg, rest, err = config.Load("woordsoek", args, search.define)
+	if err == nil && command == "" && len(rest) > 0 && rest[0] == "search" {
+		// The flags before search are parsed again with those after it. A --
+		// that ended them would end the search flags too, so it is dropped.
+		before := args[:len(args)-len(rest)]
+		if n := len(before); n > 0 && before[n-1] == "--" {
+			before = before[:n-1]
+		}
+		args, command = slices.Concat(before, rest[1:]), "search"
+		search = searchFlags{}
+		cfg, rest, err = config.Load("woordsoek", args, search.define)
+	}
+	if err == nil && command == "search" && len(rest) > 0 {
+		err = fmt.Errorf("woordsoek search: unexpected argument %q", rest[0])
+	}
+	return cfg, search, command, rest, err
+}
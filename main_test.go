@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	t.Setenv("WOORDSOEK_CONFIG", "")
+	dir := t.TempDir()
+
+	tests := []struct {
+		args         []string
+		command      string
+		locale       string
+		singleLetter string
+		rest         string
+		err          string
+	}{
+		{[]string{"-c", "o"}, "", "af-za", "o", "", ""},
+		{[]string{"search", "-c", "o", "--locale", "nl"}, "search", "nl", "o", "", ""},
+		{[]string{"--locale", "nl", "search", "-c", "o"}, "search", "nl", "o", "", ""},
+		{[]string{"--locale", "af-za", "search", "-c", "o"}, "search", "af-za", "o", "", ""},
+		// A -- before search ends the flags before it, not those after it
+		{[]string{"--locale", "nl", "--", "search", "-c", "o"}, "search", "nl", "o", "", ""},
+		{[]string{"search", "-c", "o", "door"}, "", "", "", "", `unexpected argument "door"`},
+		{[]string{"config", "show"}, "", "af-za", "", "config show", ""},
+	}
+	for _, test := range tests {
+		args := append([]string{"--dictionary-dir", dir}, test.args...)
+		cfg, search, command, rest, err := parseArgs(args)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("parseArgs(%q) returned error %v; expected %s", test.args, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseArgs(%q) returned an error: %v", test.args, err)
+			continue
+		}
+		if command != test.command || cfg.Locale != test.locale || search.singleLetter != test.singleLetter || strings.Join(rest, " ") != test.rest {
+			t.Errorf("parseArgs(%q) = command %q, locale %q, single letter %q and %q left; expected %q, %q, %q and %q",
+				test.args, command, cfg.Locale, search.singleLetter, rest, test.command, test.locale, test.singleLetter, test.rest)
+		}
+	}
+}
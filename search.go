@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/jvanrhyn/woordsoek/internal/config"
+	"github.com/jvanrhyn/woordsoek/internal/errors"
+	"github.com/jvanrhyn/woordsoek/internal/render"
+	"github.com/jvanrhyn/woordsoek/internal/tui"
+	"github.com/jvanrhyn/woordsoek/internal/woordsoek"
+)
+
+// Exit codes of woordsoek search, in the manner of grep.
+const (
+	exitFound    = 0
+	exitNotFound = 1 // the search ran but found no words
+	exitUsage    = 2 // bad flags, configuration or query
+	exitFailed   = 3 // the dictionary could not be searched
+)
+
+// searchFlags describe a search. woordsoek search prints its results; given
+// to the TUI they fill in its fields.
+type searchFlags struct {
+	singleLetter string
+	letters      string
+	length       int
+	format       string
+}
+
+// define adds the search flags to fs, each with a short and a long name.
+func (s *searchFlags) define(fs *flag.FlagSet) {
+	for _, name := range []string{"c", "single-letter"} {
+		fs.StringVar(&s.singleLetter, name, "", "the single letter every word must contain")
+	}
+	for _, name := range []string{"l", "letters"} {
+		fs.StringVar(&s.letters, name, "", "the other letters words may use")
+	}
+	for _, name := range []string{"n", "length"} {
+		fs.IntVar(&s.length, name, 0, "the length of the words, 0 for any")
+	}
+	fs.StringVar(&s.format, "format", "text", "output of woordsoek search: json, ndjson, csv or text")
+}
+
+// tuiFlags returns the search for the TUI to start with.
+func (s searchFlags) tuiFlags() tui.Flags {
+	return tui.Flags{
+		SingleLetter:  s.singleLetter,
+		SixCharString: s.letters,
+		Length:        s.length,
+	}
+}
+
+// query returns the search for locale, with the letters normalised as the
+// TUI does.
+func (s searchFlags) query(locale string) woordsoek.Query {
+	return woordsoek.Query{
+		Locale:        locale,
+		SingleLetter:  woordsoek.NormaliseLetters(locale, s.singleLetter),
+		SixCharString: woordsoek.NormaliseLetters(locale, s.letters),
+		Length:        s.length,
+	}
+}
+
+// runSearch searches the dictionary of cfg.Locale and writes the words found
+// to stdout, or what went wrong to stderr. It returns the exit code.
+func runSearch(ctx context.Context, cfg *config.Config, s searchFlags, stdout, stderr io.Writer) int {
+	format, err := render.ParseFormat(s.format)
+	if err != nil {
+		fmt.Fprintln(stderr, "woordsoek search:", errorMessage(err))
+		return exitUsage
+	}
+	if s.singleLetter == "" {
+		fmt.Fprintln(stderr, "usage: woordsoek search -c <letter> [-l <letters>] [-n <length>] [--format json|ndjson|csv|text]")
+		return exitUsage
+	}
+
+	q := s.query(cfg.Locale)
+	result, err := woordsoek.NewEngine(cfg.DictionaryDir).Search(ctx, q)
+	if err != nil {
+		fmt.Fprintln(stderr, "woordsoek search:", errorMessage(err))
+		if errors.CodeOf(err) == errors.CodeInvalidQuery {
+			return exitUsage
+		}
+		return exitFailed
+	}
+
+	if err := render.Render(stdout, format, render.Results{Query: q, Words: result.Words}); err != nil {
+		fmt.Fprintln(stderr, "woordsoek search:", err)
+		return exitFailed
+	}
+	if len(result.Words) == 0 {
+		return exitNotFound
+	}
+	return exitFound
+}
+
+// errorMessage returns the message of err without its error code.
+func errorMessage(err error) string {
+	var ce *errors.CustomError
+	if errors.As(err, &ce) {
+		return ce.Message
+	}
+	return err.Error()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jvanrhyn/woordsoek/internal/config"
+)
+
+func TestRunSearch(t *testing.T) {
+	cfg := config.Default()
+	cfg.DictionaryDir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(cfg.DictionaryDir, "af-za.txt"), []byte("adore\nroad\nrode\ndoor\nodor\ndare\n"), 0644); err != nil {
+		t.Fatalf("Failed to write dictionary: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		flags  searchFlags
+		locale string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"text", searchFlags{singleLetter: "O", letters: "aedr", length: 4, format: "text"}, "af-za", exitFound, "door\nodor\nroad\nrode\n", ""},
+		{"json", searchFlags{singleLetter: "o", letters: "aedr", length: 5, format: "json"}, "af-za", exitFound, `[{"word":"adore","length":5,"score":12,"pangram":true}]` + "\n", ""},
+		{"csv", searchFlags{singleLetter: "a", letters: "der", length: 4, format: "csv"}, "af-za", exitFound, "word,length,score,pangram\ndare,4,8,true\n", ""},
+		{"nothing found", searchFlags{singleLetter: "z", format: "text"}, "af-za", exitNotFound, "", ""},
+		{"no single letter", searchFlags{letters: "aedr", format: "text"}, "af-za", exitUsage, "", "usage: woordsoek search"},
+		{"negative length", searchFlags{singleLetter: "o", length: -1, format: "text"}, "af-za", exitUsage, "", "length cannot be negative"},
+		{"unknown format", searchFlags{singleLetter: "o", format: "xml"}, "af-za", exitUsage, "", "format must be"},
+		{"missing dictionary", searchFlags{singleLetter: "o", format: "text"}, "xx", exitFailed, "", "does not exist"},
+	}
+	for _, test := range tests {
+		cfg.Locale = test.locale
+		var stdout, stderr bytes.Buffer
+		code := runSearch(context.Background(), cfg, test.flags, &stdout, &stderr)
+		if code != test.code {
+			t.Errorf("%s: runSearch exited with %d; expected %d (stderr %q)", test.name, code, test.code, stderr.String())
+		}
+		if stdout.String() != test.stdout {
+			t.Errorf("%s: runSearch printed %q; expected %q", test.name, stdout.String(), test.stdout)
+		}
+		if !strings.Contains(stderr.String(), test.stderr) || (test.stderr == "") != (stderr.Len() == 0) {
+			t.Errorf("%s: runSearch reported %q; expected %q", test.name, stderr.String(), test.stderr)
+		}
+	}
+}